@@ -15,17 +15,17 @@
 package main
 
 import (
-	"fmt"
 	"os"
 
 	"github.com/gx-org/ccgx/internal/cmd"
-	"github.com/gx-org/ccgx/internal/gotc"
+	"github.com/gx-org/ccgx/internal/cmd/version"
 )
 
 func main() {
-	if err := gotc.Check(); err != nil {
-		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
-		os.Exit(1)
+	err := cmd.Execute()
+	if err == nil || err == version.ErrReexecuted {
+		// ErrReexecuted alone: the command succeeded in another ccgx.
+		return
 	}
-	cmd.Execute()
+	os.Exit(1)
 }
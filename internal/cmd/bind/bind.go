@@ -16,45 +16,108 @@
 package bind
 
 import (
-	"github.com/gx-org/ccgx/internal/gotc"
+	"errors"
+
+	"github.com/gx-org/ccgx/internal/cmd/carchive"
+	"github.com/gx-org/ccgx/internal/cmd/link"
+	"github.com/gx-org/ccgx/internal/cmd/lock"
+	"github.com/gx-org/ccgx/internal/cmd/version"
+	"github.com/gx-org/ccgx/internal/cmd/workspace"
 	"github.com/gx-org/ccgx/internal/gxtc"
-	gxmodule "github.com/gx-org/gx/build/module"
 	"github.com/spf13/cobra"
 )
 
-var cmake bool
+var (
+	cmake             bool
+	reexec            bool
+	ignoreVersionSkew bool
+)
 
 // Cmd is the implementation of the mod command.
 func Cmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "bind",
+		Use:   "bind [packages]",
 		Short: "Create links to dependencies, then generate C++ header files",
 		RunE:  cBind,
 	}
 	cmd.PersistentFlags().BoolVarP(&cmake, "cmake", "", false, "generate CMakeLists.txt")
+	link.AddModeFlag(cmd)
+	lock.AddLockedFlag(cmd)
+	workspace.AddTargetFlag(cmd)
+	workspace.AddModulesFlag(cmd)
+	workspace.AddKeepGoingFlag(cmd)
+	carchive.AddTrimFlag(cmd)
+	cmd.PersistentFlags().BoolVarP(&reexec, "reexec", "", false, "if ccgx is not compatible with the GX version of the module, run the ccgx tool of the module or a compatible ccgx instead")
+	cmd.PersistentFlags().BoolVarP(&ignoreVersionSkew, "ignore-version-skew", "", false, "do not check that ccgx is compatible with the GX version of the module")
 	return cmd
 }
 
 func cBind(cmd *cobra.Command, args []string) error {
-	mod, err := gxmodule.Current()
+	ws, err := workspace.Select(args)
 	if err != nil {
 		return err
 	}
-	cache, err := gotc.NewCache()
-	if err != nil {
+	if !ignoreVersionSkew {
+		if err := checkVersions(ws); err != nil {
+			return err
+		}
+	}
+	if err := lock.Check(ws); err != nil {
+		return err
+	}
+	return Run(ws, cmake)
+}
+
+// Run checks the GX imports of a workspace, links its dependencies,
+// generates the C++ bindings of its GX packages, compiles the C archive,
+// and records the inputs of the build in the lock file if not in locked mode.
+func Run(ws *gxtc.Workspace, cmake bool) error {
+	if err := gxtc.CheckImports(ws); err != nil {
 		return err
 	}
-	if err := gxtc.LinkAllDeps(mod, cache); err != nil {
+	// Write the C archive source first so that the requirements of the
+	// selected packages are kept when the dependencies are tidied.
+	if err := gxtc.WriteCArchiveSource(ws); err != nil {
+		return err
+	}
+	if err := gxtc.LinkAllDeps(ws, link.Mode); err != nil {
 		return err
 	}
 	var fs []gxtc.BinderCallback
 	if cmake {
 		fs = append(fs, gxtc.WriteCMakeLists)
 	}
-	if err := gxtc.BindAll(mod, fs); err != nil {
-		return err
+	bindErr := gxtc.BindAll(ws, fs)
+	var pkgErrs *gxtc.PackageErrors
+	if bindErr != nil && !errors.As(bindErr, &pkgErrs) {
+		return bindErr
+	}
+	// In keep-going mode, the C archive is built even if some packages failed.
+	if err := gxtc.CompileCArchive(ws); err != nil {
+		return errors.Join(bindErr, err)
 	}
-	if err := gxtc.CompileCArchive(mod); err != nil {
+	if bindErr != nil {
+		return bindErr
+	}
+	if lock.Locked {
+		// The build has been checked against the lock file.
+		return nil
+	}
+	return lock.Write(ws)
+}
+
+func checkVersions(ws *gxtc.Workspace) error {
+	for _, mod := range ws.Modules {
+		err := version.Check(mod)
+		if err == nil {
+			continue
+		}
+		if !reexec {
+			return err
+		}
+		if rerr := version.Reexec(ws.Main); rerr != nil {
+			return rerr
+		}
 		return err
 	}
 	return nil
@@ -18,25 +18,67 @@
 package carchive
 
 import (
+	"fmt"
+
+	"github.com/gx-org/ccgx/internal/cmd/lock"
+	"github.com/gx-org/ccgx/internal/cmd/workspace"
+	"github.com/gx-org/ccgx/internal/diag"
 	"github.com/gx-org/ccgx/internal/gxtc"
-	gxmodule "github.com/gx-org/gx/build/module"
 	"github.com/spf13/cobra"
 )
 
+var sizeReport bool
+
+// AddTrimFlag adds the flag to only import the bound packages in the C archive.
+func AddTrimFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().BoolVarP(&gxtc.TrimImports, "trim-imports", "", false, "only import in the C archive the selected packages and what they transitively need")
+}
+
 // Cmd is the implementation of the mod command.
 func Cmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "carchive",
+	cmd := &cobra.Command{
+		Use:   "carchive [packages]",
 		Short: "Create a c archive",
 		Long:  "First, create a carchive.go file which includes all the Go/GX dependencies and a main function. This file is then compile using `go build -buildmode=c-archive` to produce a binary static .a library file.",
 		RunE:  cArchive,
 	}
+	lock.AddLockedFlag(cmd)
+	workspace.AddTargetFlag(cmd)
+	AddTrimFlag(cmd)
+	cmd.PersistentFlags().BoolVarP(&sizeReport, "size-report", "", false, "print the size of the C archive per Go package")
+	return cmd
 }
 
 func cArchive(cmd *cobra.Command, args []string) error {
-	mod, err := gxmodule.Current()
+	ws, err := workspace.Select(args)
 	if err != nil {
 		return err
 	}
-	return gxtc.CompileCArchive(mod)
+	if err := lock.Check(ws); err != nil {
+		return err
+	}
+	if err := gxtc.CheckImports(ws); err != nil {
+		return err
+	}
+	if err := gxtc.CompileCArchive(ws); err != nil {
+		return err
+	}
+	if !sizeReport {
+		return nil
+	}
+	w := cmd.OutOrStdout()
+	for i, path := range gxtc.ArchivePaths(ws) {
+		report, err := gxtc.SizeReport(path)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s:\n", diag.RelPath(path))
+		if err := gxtc.WriteSizeReport(w, report); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,153 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debug
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TraceFile is the file in which the trace of all the operations is written
+// in the Chrome trace event format. Nothing is written if empty.
+var TraceFile string
+
+// Categories of traced operations.
+const (
+	// Exec is a subprocess.
+	Exec = "exec"
+	// Write is a file written by ccgx.
+	Write = "write"
+	// Step is a step of the ccgx pipeline.
+	Step = "step"
+)
+
+// Span is an operation traced with its duration.
+// A nil span is valid and traces nothing.
+type Span struct {
+	cat   string
+	name  string
+	args  map[string]string
+	start time.Time
+}
+
+// Start starts tracing an operation.
+// Returns nil if neither debugging nor tracing is enabled.
+func Start(cat, name string) *Span {
+	if !Debug && TraceFile == "" {
+		return nil
+	}
+	return &Span{cat: cat, name: name, start: time.Now()}
+}
+
+// Set sets an argument of the operation.
+func (span *Span) Set(key, value string) *Span {
+	if span == nil {
+		return nil
+	}
+	if span.args == nil {
+		span.args = make(map[string]string)
+	}
+	span.args[key] = value
+	return span
+}
+
+// End ends the operation and reports its duration.
+// err is the error returned by the operation, if any.
+func (span *Span) End(err error) {
+	if span == nil {
+		return
+	}
+	dur := time.Since(span.start)
+	if err != nil {
+		span.Set("error", err.Error())
+	}
+	if Debug {
+		var b strings.Builder
+		fmt.Fprintf(&b, "DEBUG %s %s (%v)", span.cat, span.name, dur.Round(time.Microsecond))
+		keys := make([]string, 0, len(span.args))
+		for key := range span.args {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			fmt.Fprintf(&b, "\n\t%s: %s", key, span.args[key])
+		}
+		log.Println(b.String())
+	}
+	if TraceFile != "" {
+		trace.add(span, dur)
+	}
+}
+
+// event is a complete event of the Chrome trace event format.
+type event struct {
+	Name string            `json:"name"`
+	Cat  string            `json:"cat"`
+	Ph   string            `json:"ph"`
+	TS   int64             `json:"ts"`
+	Dur  int64             `json:"dur"`
+	PID  int               `json:"pid"`
+	TID  int               `json:"tid"`
+	Args map[string]string `json:"args,omitempty"`
+}
+
+type tracer struct {
+	mut    sync.Mutex
+	origin time.Time
+	events []event
+}
+
+var trace = tracer{origin: time.Now()}
+
+func (t *tracer) add(span *Span, dur time.Duration) {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	t.events = append(t.events, event{
+		Name: span.name,
+		Cat:  span.cat,
+		Ph:   "X",
+		TS:   span.start.Sub(t.origin).Microseconds(),
+		Dur:  dur.Microseconds(),
+		PID:  os.Getpid(),
+		TID:  1,
+		Args: span.args,
+	})
+}
+
+// WriteTrace writes the operations traced so far in TraceFile.
+// Does nothing if TraceFile is empty.
+func WriteTrace() error {
+	if TraceFile == "" {
+		return nil
+	}
+	trace.mut.Lock()
+	defer trace.mut.Unlock()
+	data, err := json.MarshalIndent(struct {
+		TraceEvents []event `json:"traceEvents"`
+	}{TraceEvents: trace.events}, "", " ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(TraceFile, data, 0644); err != nil {
+		return fmt.Errorf("cannot write trace: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,285 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package doctor provides the Cobra doctor command.
+// The doctor command checks that the environment can build GX C++ bindings
+// and reports how to fix any problem found.
+package doctor
+
+import (
+	"errors"
+	"fmt"
+	"go/version"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gx-org/ccgx/internal/exec"
+	"github.com/gx-org/ccgx/internal/gotc"
+	gxmodule "github.com/gx-org/gx/build/module"
+	"github.com/spf13/cobra"
+)
+
+// Cmd is the implementation of the doctor command.
+func Cmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the environment and report how to fix problems",
+		RunE:  cDoctor,
+		// Go is checked by the doctor to report how to fix it.
+		Annotations: map[string]string{gotc.NoCheck: ""},
+	}
+}
+
+type status string
+
+const (
+	statusOK   status = "ok"
+	statusFail status = "FAIL"
+	statusSkip status = "skip"
+)
+
+type result struct {
+	status  status
+	details string
+	hint    string
+}
+
+func ok(format string, a ...any) result {
+	return result{status: statusOK, details: fmt.Sprintf(format, a...)}
+}
+
+func fail(hint, format string, a ...any) result {
+	return result{status: statusFail, details: fmt.Sprintf(format, a...), hint: hint}
+}
+
+func skip(format string, a ...any) result {
+	return result{status: statusSkip, details: fmt.Sprintf(format, a...)}
+}
+
+type check struct {
+	name string
+	run  func(env map[string]string) result
+	// needsGo is true if the check reads the Go environment.
+	needsGo bool
+}
+
+var checks = []check{
+	{name: "go version", run: checkGoVersion, needsGo: true},
+	{name: "cgo", run: checkCGo, needsGo: true},
+	{name: "C compiler", run: checkCompiler("CC"), needsGo: true},
+	{name: "C++ compiler", run: checkCompiler("CXX"), needsGo: true},
+	{name: "cmake", run: checkCMake},
+	{name: "absl", run: checkAbsl},
+	{name: "GOPJRT_INSTALL_DIR", run: checkGoPJRT},
+	{name: "CGO_CFLAGS", run: checkCGoCFlags, needsGo: true},
+	{name: "GOMODCACHE", run: checkModCache, needsGo: true},
+}
+
+var goEnvKeys = []string{"GOVERSION", "CGO_ENABLED", "CC", "CXX", "CGO_CFLAGS", "CGO_CXXFLAGS", "GOMODCACHE"}
+
+func cDoctor(cmd *cobra.Command, args []string) error {
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "CHECK\tSTATUS\tDETAILS")
+	var hints []string
+	report := func(name string, res result) {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", name, res.status, res.details)
+		if res.status == statusFail {
+			hints = append(hints, fmt.Sprintf("%s: %s", name, res.hint))
+		}
+	}
+	env, goErr := gotc.Env(goEnvKeys...)
+	if goErr != nil {
+		report("go", fail("install Go (see https://go.dev/dl/) and add it to the PATH, or select a go binary with --go", "%v", goErr))
+	}
+	for _, chk := range checks {
+		if goErr != nil && chk.needsGo {
+			report(chk.name, skip("go not available"))
+			continue
+		}
+		report(chk.name, chk.run(env))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if len(hints) == 0 {
+		return nil
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), "\nTo fix the problems above:")
+	for _, hint := range hints {
+		fmt.Fprintf(cmd.OutOrStdout(), "  %s\n", hint)
+	}
+	return fmt.Errorf("%d check(s) failed", len(hints))
+}
+
+func checkGoVersion(env map[string]string) result {
+	goVersion := env["GOVERSION"]
+	mod, err := gxmodule.New("")
+	if err != nil {
+		return ok("%s (no go.mod found)", goVersion)
+	}
+	if mod.File().Go == nil {
+		return ok("%s (no go directive in go.mod)", goVersion)
+	}
+	want := "go" + mod.File().Go.Version
+	if version.Compare(goVersion, want) < 0 {
+		return fail(
+			fmt.Sprintf("install %s or later (see https://go.dev/dl/) or set GOTOOLCHAIN=auto", want),
+			"%s is older than %s required by go.mod", goVersion, want)
+	}
+	return ok("%s (go.mod requires %s)", goVersion, want)
+}
+
+func checkCGo(env map[string]string) result {
+	if env["CGO_ENABLED"] != "1" {
+		return fail("run `go env -w CGO_ENABLED=1` and make sure a C compiler is installed", "CGO_ENABLED=%q", env["CGO_ENABLED"])
+	}
+	return ok("CGO_ENABLED=1")
+}
+
+func checkCompiler(key string) func(map[string]string) result {
+	return func(env map[string]string) result {
+		fields := strings.Fields(env[key])
+		if len(fields) == 0 {
+			return fail(fmt.Sprintf("set %s with `go env -w %s=<compiler>`", key, key), "%s not set", key)
+		}
+		path, err := exec.LookPath(fields[0])
+		if err != nil {
+			return fail(fmt.Sprintf("install %s (e.g. gcc/g++ or clang/clang++) or set %s to an installed compiler", fields[0], key), "%s=%s not found", key, fields[0])
+		}
+		return ok("%s=%s (%s)", key, env[key], path)
+	}
+}
+
+func checkCMake(env map[string]string) result {
+	path, err := exec.LookPath("cmake")
+	if err != nil {
+		return fail("install cmake 3.24 or later (see https://cmake.org/download/)", "cmake not found in PATH")
+	}
+	out, err := exec.Output(exec.CommandContext(gotc.Context, path, "--version"))
+	if err != nil {
+		return fail("reinstall cmake", "cannot run %s: %v", path, err)
+	}
+	firstLine, _, _ := strings.Cut(string(out), "\n")
+	return ok("%s (%s)", firstLine, path)
+}
+
+// includeDirs returns the folders in which a C/C++ compiler looks for headers.
+func includeDirs(env map[string]string) []string {
+	var dirs []string
+	for _, key := range []string{"CGO_CFLAGS", "CGO_CXXFLAGS"} {
+		fields := strings.Fields(env[key])
+		for i, field := range fields {
+			if field == "-I" && i+1 < len(fields) {
+				dirs = append(dirs, fields[i+1])
+			} else if dir, found := strings.CutPrefix(field, "-I"); found && dir != "" {
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+	for _, key := range []string{"CPATH", "C_INCLUDE_PATH", "CPLUS_INCLUDE_PATH"} {
+		dirs = append(dirs, filepath.SplitList(os.Getenv(key))...)
+	}
+	for _, prefix := range filepath.SplitList(os.Getenv("CMAKE_PREFIX_PATH")) {
+		dirs = append(dirs, filepath.Join(prefix, "include"))
+	}
+	dirs = append(dirs, "/usr/local/include", "/usr/include", "/opt/homebrew/include")
+	return slices.DeleteFunc(dirs, func(dir string) bool { return dir == "" })
+}
+
+func checkAbsl(env map[string]string) result {
+	const header = "absl/base/config.h"
+	for _, dir := range includeDirs(env) {
+		if _, err := os.Stat(filepath.Join(dir, header)); err == nil {
+			return ok("%s found in %s", header, dir)
+		}
+	}
+	return fail("install abseil-cpp (e.g. `apt install libabsl-dev`) or add its include folder to CMAKE_PREFIX_PATH or CGO_CXXFLAGS", "%s not found", header)
+}
+
+const goPJRTKey = "GOPJRT_INSTALL_DIR"
+
+const goPJRTHint = "install gopjrt and set GOPJRT_INSTALL_DIR (see the README)"
+
+func checkGoPJRT(env map[string]string) result {
+	dir := os.Getenv(goPJRTKey)
+	if dir == "" {
+		return fail(goPJRTHint, "%s not set", goPJRTKey)
+	}
+	for _, sub := range []string{"include", "lib"} {
+		if info, err := os.Stat(filepath.Join(dir, sub)); err != nil || !info.IsDir() {
+			return fail(goPJRTHint, "%s not found", filepath.Join(dir, sub))
+		}
+	}
+	libs, _ := filepath.Glob(filepath.Join(dir, "lib", "libgomlx_xlabuilder*"))
+	if len(libs) == 0 {
+		return fail(goPJRTHint, "gomlx_xlabuilder library not found in %s", filepath.Join(dir, "lib"))
+	}
+	libDir := filepath.Join(dir, "lib")
+	libPathKey := libraryPathKey()
+	if !slices.Contains(filepath.SplitList(os.Getenv(libPathKey)), libDir) {
+		return fail(fmt.Sprintf("export %s=%s", libPathKey, libDir), "%s not in %s", libDir, libPathKey)
+	}
+	return ok("%s", dir)
+}
+
+// libraryPathKey returns the environment variable listing the folders
+// in which the dynamic loader looks for shared libraries.
+func libraryPathKey() string {
+	if runtime.GOOS == "darwin" {
+		return "DYLD_LIBRARY_PATH"
+	}
+	return "LD_LIBRARY_PATH"
+}
+
+func checkCGoCFlags(env map[string]string) result {
+	dir := os.Getenv(goPJRTKey)
+	if dir == "" {
+		return skip("%s not set", goPJRTKey)
+	}
+	include := filepath.Join(dir, "include")
+	hint := fmt.Sprintf(`export CGO_CFLAGS="-I %s"`, include)
+	if !slices.Contains(includeDirs(map[string]string{"CGO_CFLAGS": env["CGO_CFLAGS"]}), include) {
+		return fail(hint, "%s not in CGO_CFLAGS=%q", include, env["CGO_CFLAGS"])
+	}
+	headers, _ := filepath.Glob(filepath.Join(include, "gomlx", "*"))
+	if len(headers) == 0 {
+		return fail(goPJRTHint, "no gomlx header found in %s", include)
+	}
+	return ok("%s", env["CGO_CFLAGS"])
+}
+
+func checkModCache(env map[string]string) result {
+	dir := env["GOMODCACHE"]
+	hint := "set GOMODCACHE to a writable folder with `go env -w GOMODCACHE=<folder>`"
+	if dir == "" {
+		return fail(hint, "GOMODCACHE not set")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fail(hint, "cannot create %s: %v", dir, err)
+	}
+	f, err := os.CreateTemp(dir, ".ccgx-doctor-*")
+	if err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			return fail(hint, "%s is not writable", dir)
+		}
+		return fail(hint, "cannot write in %s: %v", dir, err)
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return ok("%s", dir)
+}
@@ -17,29 +17,34 @@
 package link
 
 import (
-	"github.com/gx-org/ccgx/internal/gotc"
+	"github.com/gx-org/ccgx/internal/cmd/workspace"
 	"github.com/gx-org/ccgx/internal/gxtc"
-	gxmodule "github.com/gx-org/gx/build/module"
 	"github.com/spf13/cobra"
 )
 
+// Mode specifies how dependencies are linked.
+var Mode = gxtc.LinkAbsolute
+
+// AddModeFlag adds the flag to select how dependencies are linked to a command.
+func AddModeFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().VarP(&Mode, "link-mode", "", "how dependencies are installed in gxdeps: absolute, relative, or copy")
+}
+
 // Cmd is the implementation of the mod command.
 func Cmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "link",
 		Short: "Link dependencies, then generate C++ header files",
 		RunE:  cLink,
 	}
+	AddModeFlag(cmd)
+	return cmd
 }
 
 func cLink(cmd *cobra.Command, args []string) error {
-	mod, err := gxmodule.Current()
-	if err != nil {
-		return err
-	}
-	cache, err := gotc.NewCache()
+	ws, err := workspace.Current()
 	if err != nil {
 		return err
 	}
-	return gxtc.LinkAllDeps(mod, cache)
+	return gxtc.LinkAllDeps(ws, Mode)
 }
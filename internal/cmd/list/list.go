@@ -0,0 +1,66 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package list provides the Cobra list command.
+// The list command prints the GX packages of the current module.
+package list
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gx-org/ccgx/internal/cmd/workspace"
+	"github.com/gx-org/ccgx/internal/gxtc"
+	"github.com/spf13/cobra"
+)
+
+var jsonOutput bool
+
+// Cmd is the implementation of the list command.
+func Cmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list [packages]",
+		Short: "List GX packages",
+		Long:  "Print the import path of the GX packages of the module, one per line. With --json, print a JSON object per package with its folder, source files, GX imports, Go packager, generated C++ files, and exported functions.",
+		RunE:  cList,
+	}
+	cmd.Flags().BoolVarP(&jsonOutput, "json", "", false, "print the packages in JSON")
+	return cmd
+}
+
+func cList(cmd *cobra.Command, args []string) error {
+	ws, err := workspace.Select(args)
+	if err != nil {
+		return err
+	}
+	pkgs, err := gxtc.List(ws, jsonOutput)
+	if err != nil {
+		return err
+	}
+	w := cmd.OutOrStdout()
+	if !jsonOutput {
+		for _, pkg := range pkgs {
+			fmt.Fprintln(w, pkg.ImportPath)
+		}
+		return nil
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	for _, pkg := range pkgs {
+		if err := enc.Encode(pkg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
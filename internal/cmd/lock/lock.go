@@ -0,0 +1,219 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lock records the inputs of a build in the ccgx.lock file
+// of a module so that a build can be reproduced on another machine.
+package lock
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/gx-org/ccgx/internal/cmd/version"
+	"github.com/gx-org/ccgx/internal/gotc"
+	"github.com/gx-org/ccgx/internal/gxtc"
+	"github.com/spf13/cobra"
+)
+
+// FileName is the name of the lock file at the root of a module.
+const FileName = "ccgx.lock"
+
+// Locked refuses to build if the environment differs from the lock file.
+var Locked bool
+
+// AddLockedFlag adds the flag to build in locked mode to a command.
+func AddLockedFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().BoolVarP(&Locked, "locked", "", false, "refuse to build if the environment differs from "+FileName)
+}
+
+// Lock is the content of a lock file.
+type Lock struct {
+	// Go is the version of the Go toolchain.
+	Go string `json:"go"`
+	// CCGX is the version of ccgx.
+	CCGX string `json:"ccgx"`
+	// Modules are the versions of GX and its backend required by the module.
+	Modules map[string]string `json:"modules"`
+	// Flags are the environment variables used to build the C archive.
+	Flags map[string]string `json:"flags"`
+	// GoSum is the SHA-256 hash of the go.sum files of the workspace.
+	GoSum string `json:"goSum"`
+}
+
+var lockedModules = []string{version.GXPath, version.BackendPath, version.XLAPJRTPath}
+
+var flagKeys = []string{"GOOS", "GOARCH", "GOFLAGS", "CC", "CXX", "CGO_ENABLED", "CGO_CFLAGS", "CGO_CXXFLAGS", "CGO_LDFLAGS"}
+
+// Current returns the lock of the current environment.
+func Current(ws *gxtc.Workspace) (*Lock, error) {
+	// Flags set by ccgx itself, for example when running offline, are not locked.
+	env, err := gotc.UserEnv(append([]string{"GOVERSION"}, flagKeys...)...)
+	if err != nil {
+		return nil, err
+	}
+	project := version.Project(ws.Main)
+	lck := &Lock{
+		Go:      env["GOVERSION"],
+		CCGX:    version.CCGX()[version.CCGXPath],
+		Modules: make(map[string]string),
+		Flags:   make(map[string]string),
+	}
+	for _, path := range lockedModules {
+		if v := project[path]; v != "" {
+			lck.Modules[path] = v
+		}
+	}
+	for _, key := range flagKeys {
+		lck.Flags[key] = withoutIncludeDirs(env[key])
+	}
+	if lck.GoSum, err = goSumHash(ws); err != nil {
+		return nil, err
+	}
+	return lck, nil
+}
+
+// withoutIncludeDirs removes the -I flags from compiler flags.
+// Include folders depend on the machine and are not locked.
+func withoutIncludeDirs(flags string) string {
+	fields := strings.Fields(flags)
+	var kept []string
+	for i := 0; i < len(fields); i++ {
+		switch field := fields[i]; {
+		case field == "-I":
+			i++
+		case strings.HasPrefix(field, "-I"):
+		default:
+			kept = append(kept, field)
+		}
+	}
+	return strings.Join(kept, " ")
+}
+
+// goSumHash returns the hash of the go.sum files of the modules of a workspace
+// and of the go.work.sum file of the workspace.
+func goSumHash(ws *gxtc.Workspace) (string, error) {
+	var paths []string
+	for _, mod := range ws.Modules {
+		paths = append(paths, filepath.Join(mod.Root(), "go.sum"))
+	}
+	if ws.WorkFile != "" {
+		paths = append(paths, ws.WorkFile+".sum")
+	}
+	h := sha256.New()
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		h.Write(data)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Read reads the lock file at the root of a module.
+func Read(root string) (*Lock, error) {
+	path := filepath.Join(root, FileName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	lck := &Lock{}
+	if err := json.Unmarshal(data, lck); err != nil {
+		return nil, fmt.Errorf("cannot parse %s: %v", path, err)
+	}
+	return lck, nil
+}
+
+// Write writes the lock of the current environment at the root of
+// the main module of a workspace.
+func Write(ws *gxtc.Workspace) error {
+	lck, err := Current(ws)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(lck, "", "  ")
+	if err != nil {
+		return err
+	}
+	return gxtc.WriteFile(filepath.Join(ws.Main.Root(), FileName), append(data, '\n'), 0644)
+}
+
+// Check returns an error if the current environment differs from the
+// lock file of the main module of a workspace. Does nothing if not in
+// locked mode.
+func Check(ws *gxtc.Workspace) error {
+	if !Locked {
+		return nil
+	}
+	want, err := Read(ws.Main.Root())
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("cannot build in locked mode: %s not found in %s", FileName, ws.Main.Root())
+	}
+	if err != nil {
+		return err
+	}
+	got, err := Current(ws)
+	if err != nil {
+		return err
+	}
+	diffs := want.diff(got)
+	if len(diffs) == 0 {
+		// The go command must not update go.mod and go.sum files.
+		gotc.ReadOnly = true
+		return nil
+	}
+	return fmt.Errorf("cannot build in locked mode: the environment differs from %s:\n\t%s",
+		filepath.Join(ws.Main.Root(), FileName), strings.Join(diffs, "\n\t"))
+}
+
+// diff returns the differences between two locks.
+func (lck *Lock) diff(other *Lock) []string {
+	var diffs []string
+	add := func(name, want, got string) {
+		if want != got {
+			diffs = append(diffs, fmt.Sprintf("%s: locked %q, got %q", name, want, got))
+		}
+	}
+	add("go", lck.Go, other.Go)
+	add("ccgx", lck.CCGX, other.CCGX)
+	diffMaps := func(want, got map[string]string) {
+		var keys []string
+		for key := range want {
+			keys = append(keys, key)
+		}
+		for key := range got {
+			if _, ok := want[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			add(key, want[key], got[key])
+		}
+	}
+	diffMaps(lck.Modules, other.Modules)
+	diffMaps(lck.Flags, other.Flags)
+	add("go.sum", lck.GoSum, other.GoSum)
+	return diffs
+}
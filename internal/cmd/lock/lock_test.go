@@ -0,0 +1,102 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lock
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestWithoutIncludeDirs(t *testing.T) {
+	tests := []struct {
+		flags string
+		want  string
+	}{
+		{flags: "", want: ""},
+		{flags: "-O2 -g", want: "-O2 -g"},
+		{flags: "-I /opt/gopjrt/include", want: ""},
+		{flags: "-I/opt/gopjrt/include -O2", want: "-O2"},
+		{flags: "-O2 -I /a  -DX=1 -I/b", want: "-O2 -DX=1"},
+		{flags: "-O2 -I", want: "-O2"},
+		{flags: "-mod=mod", want: "-mod=mod"},
+	}
+	for _, test := range tests {
+		if got := withoutIncludeDirs(test.flags); got != test.want {
+			t.Errorf("withoutIncludeDirs(%q) = %q, want %q", test.flags, got, test.want)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	base := func() *Lock {
+		return &Lock{
+			Go:      "go1.24.4",
+			CCGX:    "v0.3.0",
+			Modules: map[string]string{"github.com/gx-org/gx": "v0.6.1"},
+			Flags:   map[string]string{"GOFLAGS": "", "CGO_CFLAGS": "-O2"},
+			GoSum:   "sha256:abc",
+		}
+	}
+	tests := []struct {
+		name   string
+		change func(*Lock)
+		want   []string
+	}{
+		{
+			name:   "same",
+			change: func(*Lock) {},
+		},
+		{
+			name:   "go",
+			change: func(lck *Lock) { lck.Go = "go1.25.0" },
+			want:   []string{`go: locked "go1.24.4", got "go1.25.0"`},
+		},
+		{
+			name:   "module version",
+			change: func(lck *Lock) { lck.Modules["github.com/gx-org/gx"] = "v0.6.2" },
+			want:   []string{`github.com/gx-org/gx: locked "v0.6.1", got "v0.6.2"`},
+		},
+		{
+			name:   "new module",
+			change: func(lck *Lock) { lck.Modules["github.com/gx-org/xlapjrt"] = "v0.2.3" },
+			want:   []string{`github.com/gx-org/xlapjrt: locked "", got "v0.2.3"`},
+		},
+		{
+			name: "flags",
+			change: func(lck *Lock) {
+				lck.Flags["GOFLAGS"] = "-mod=mod"
+				delete(lck.Flags, "CGO_CFLAGS")
+			},
+			want: []string{
+				`CGO_CFLAGS: locked "-O2", got ""`,
+				`GOFLAGS: locked "", got "-mod=mod"`,
+			},
+		},
+		{
+			name:   "go.sum",
+			change: func(lck *Lock) { lck.GoSum = "sha256:def" },
+			want:   []string{`go.sum: locked "sha256:abc", got "sha256:def"`},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := base()
+			test.change(got)
+			if diffs := base().diff(got); !slices.Equal(diffs, test.want) {
+				t.Errorf("diff() = %q, want %q", diffs, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mod
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/gx-org/ccgx/internal/cmd/workspace"
+	"github.com/gx-org/ccgx/internal/gxtc"
+	"github.com/spf13/cobra"
+)
+
+func cmdDeps() *cobra.Command {
+	return &cobra.Command{
+		Use:   "deps",
+		Short: "report which dependencies are linked in gxdeps and why",
+		RunE:  cDeps,
+	}
+}
+
+func cDeps(cmd *cobra.Command, args []string) error {
+	ws, err := workspace.Current()
+	if err != nil {
+		return err
+	}
+	deps, err := gxtc.Deps(ws)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "MODULE\tLINKED\tREASON")
+	for _, dep := range deps {
+		mod := dep.Path
+		if dep.Version != "" {
+			mod += "@" + dep.Version
+		}
+		linked := "no"
+		if dep.Linked() {
+			linked = "yes"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", mod, linked, dep.Reason())
+	}
+	return w.Flush()
+}
@@ -0,0 +1,127 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mod
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gx-org/ccgx/internal/cmd/link"
+	"github.com/gx-org/ccgx/internal/cmd/workspace"
+	"github.com/gx-org/ccgx/internal/gotc"
+	"github.com/gx-org/ccgx/internal/gxtc"
+	"github.com/spf13/cobra"
+	"golang.org/x/mod/modfile"
+	gomodule "golang.org/x/mod/module"
+)
+
+func cmdGet() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "get module[@version]...",
+		Short: "Add GX dependencies, then pack, tidy, and link them",
+		Long:  "Require modules with go get and check that they provide GX packages. The GX packages are then packed, the requirements tidied, and the dependencies linked in gxdeps. go.mod and go.sum are restored if a module has no GX package or if a step fails.",
+		RunE:  cGet,
+		Args:  cobra.MinimumNArgs(1),
+	}
+	link.AddModeFlag(cmd)
+	return cmd
+}
+
+func cGet(cmd *cobra.Command, args []string) error {
+	ws, err := workspace.Current()
+	if err != nil {
+		return err
+	}
+	restore, err := saveModFiles(ws)
+	if err != nil {
+		return err
+	}
+	if err := get(ws, args); err != nil {
+		return errors.Join(err, restore())
+	}
+	return nil
+}
+
+func get(ws *gxtc.Workspace, args []string) error {
+	root := ws.Main.Root()
+	if err := gotc.Get(root, args...); err != nil {
+		return err
+	}
+	ws, err := workspace.Reload(ws)
+	if err != nil {
+		return err
+	}
+	// The modules are kept when the dependencies are tidied
+	// even if no GX package imports them yet.
+	if ws.Require, err = checkGXModules(ws, args); err != nil {
+		return err
+	}
+	if err := gxtc.PackAll(ws); err != nil {
+		return err
+	}
+	// Keep the requirements of the C archive when the dependencies are tidied.
+	if err := gxtc.WriteCArchiveSource(ws); err != nil {
+		return err
+	}
+	if gotc.VendorDir(root) != "" {
+		err = gxtc.Vendor(ws)
+	} else {
+		err = gxtc.ModTidy(ws)
+	}
+	if err != nil {
+		return err
+	}
+	// Read the requirements updated by go mod tidy.
+	if ws, err = workspace.Reload(ws); err != nil {
+		return err
+	}
+	return gxtc.LinkAllDeps(ws, link.Mode)
+}
+
+// checkGXModules checks that the modules given to go get provide GX packages.
+// Returns the modules with the versions selected by go get.
+func checkGXModules(ws *gxtc.Workspace, args []string) ([]gomodule.Version, error) {
+	var mods []*gomodule.Version
+	for _, arg := range args {
+		path, _, _ := strings.Cut(arg, "@")
+		var found *modfile.Require
+		for _, req := range ws.Main.File().Require {
+			if path != req.Mod.Path && !strings.HasPrefix(path, req.Mod.Path+"/") {
+				continue
+			}
+			// Nested modules: select the longest module path.
+			if found == nil || len(req.Mod.Path) > len(found.Mod.Path) {
+				found = req
+			}
+		}
+		if found == nil {
+			return nil, fmt.Errorf("no module providing %s is required by %s", path, ws.Main.Name())
+		}
+		mods = append(mods, &found.Mod)
+	}
+	deps, err := gxtc.ModuleDeps(ws, mods)
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]gomodule.Version, len(deps))
+	for i, dep := range deps {
+		if dep.GXFiles == 0 {
+			return nil, fmt.Errorf("module %s %s has no GX package", dep.Path, dep.Version)
+		}
+		versions[i] = gomodule.Version{Path: dep.Path, Version: dep.Version}
+	}
+	return versions, nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mod
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gx-org/ccgx/internal/cmd/workspace"
+	"github.com/gx-org/ccgx/internal/gxtc"
+	"github.com/spf13/cobra"
+)
+
+var graphDOT bool
+
+func cmdGraph() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "graph [packages]",
+		Short: "Print the GX package import graph",
+		Long:  "Print the imports of the GX packages imported by the C archive, one \"package import\" line per edge, including the packages of dependency modules and of the GX standard library. Use --dot to print the graph in the Graphviz DOT language.",
+		RunE:  cGraph,
+	}
+	cmd.Flags().BoolVarP(&graphDOT, "dot", "", false, "print the graph in the Graphviz DOT language")
+	return cmd
+}
+
+func cGraph(cmd *cobra.Command, args []string) error {
+	ws, err := workspace.Select(args)
+	if err != nil {
+		return err
+	}
+	g, err := gxtc.NewImportGraph(ws)
+	if err != nil {
+		return err
+	}
+	if graphDOT {
+		return g.WriteDOT(cmd.OutOrStdout())
+	}
+	return g.WriteText(cmd.OutOrStdout())
+}
+
+func cmdWhy() *cobra.Command {
+	return &cobra.Command{
+		Use:   "why package|module...",
+		Short: "Explain why GX packages or modules are needed by the C archive",
+		Long:  "Print the shortest chain of GX imports from the C archive to a package, or to any package of a module.",
+		RunE:  cWhy,
+		Args:  cobra.MinimumNArgs(1),
+	}
+}
+
+func cWhy(cmd *cobra.Command, args []string) error {
+	ws, err := workspace.Current()
+	if err != nil {
+		return err
+	}
+	// Each target has its own C archive.
+	sources := []string{"gxdeps/carchive.go"}
+	wss := []*gxtc.Workspace{ws}
+	if len(ws.Targets) > 0 {
+		sources, wss = nil, nil
+		for _, target := range ws.Targets {
+			sources = append(sources, "gxdeps/"+target.Name+"/carchive.go")
+			wss = append(wss, target.Workspace())
+		}
+	}
+	graphs := make([]*gxtc.ImportGraph, len(wss))
+	for i, ws := range wss {
+		if graphs[i], err = gxtc.NewImportGraph(ws); err != nil {
+			return err
+		}
+	}
+	w := cmd.OutOrStdout()
+	for i, arg := range args {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "# %s\n", arg)
+		needed := false
+		for j, g := range graphs {
+			chain := g.Why(func(pkg string) bool { return pkg == arg })
+			if chain == nil {
+				// Not a package of the graph: look for the packages of a module.
+				chain = g.Why(func(pkg string) bool { return strings.HasPrefix(pkg, arg+"/") })
+			}
+			if chain == nil {
+				continue
+			}
+			needed = true
+			fmt.Fprintln(w, sources[j])
+			for _, pkg := range chain {
+				fmt.Fprintln(w, pkg)
+			}
+		}
+		if !needed {
+			fmt.Fprintf(w, "(the C archive does not need %s)\n", arg)
+		}
+	}
+	return nil
+}
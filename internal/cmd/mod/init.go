@@ -15,34 +15,67 @@
 package mod
 
 import (
+	"github.com/gx-org/ccgx/internal/cmd/version"
+	"github.com/gx-org/ccgx/internal/cmd/workspace"
 	"github.com/gx-org/ccgx/internal/gotc"
 	"github.com/gx-org/ccgx/internal/gxtc"
-	"github.com/gx-org/gx/build/module"
 	"github.com/spf13/cobra"
 )
 
-var initOverwriteFile bool
+var (
+	initOverwriteFile  bool
+	initGXVersion      string
+	initBackendVersion string
+)
 
 func cmdInit() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "init",
 		Short: "create gx.mod",
 		RunE:  cInit,
 		Args:  cobra.ExactArgs(1),
 	}
+	cmd.Flags().StringVarP(&initGXVersion, "gx-version", "", "", "version of "+version.GXPath+" to require (resolved by go mod tidy if empty)")
+	cmd.Flags().StringVarP(&initBackendVersion, "backend-version", "", "", "version of "+version.XLAPJRTPath+" to require (resolved by go mod tidy if empty)")
+	return cmd
+}
+
+// pins returns the go get arguments requiring given GX and backend versions.
+// Empty versions are skipped.
+func pins(gxVersion, backendVersion string) []string {
+	var args []string
+	if gxVersion != "" {
+		args = append(args, version.GXPath+"@"+gxVersion)
+	}
+	if backendVersion != "" {
+		args = append(args, version.XLAPJRTPath+"@"+backendVersion)
+	}
+	return args
 }
 
 func cInit(cmd *cobra.Command, args []string) error {
 	if err := gotc.ModInit(args[0]); err != nil {
 		return err
 	}
-	mod, err := module.Current()
+	if pinArgs := pins(initGXVersion, initBackendVersion); len(pinArgs) > 0 {
+		if err := gotc.Get("", pinArgs...); err != nil {
+			return err
+		}
+	}
+	ws, err := workspace.Current()
 	if err != nil {
 		return err
 	}
-	if err := gxtc.PackAll(mod); err != nil {
+	if err := gxtc.PackAll(ws); err != nil {
 		return err
 	}
+	if initBackendVersion != "" {
+		// The backend is only imported by the C archive:
+		// write its source so that go mod tidy keeps the requirement.
+		if err := gxtc.WriteCArchiveSource(ws); err != nil {
+			return err
+		}
+	}
 	if err := gotc.ModTidy(); err != nil {
 		return err
 	}
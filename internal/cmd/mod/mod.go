@@ -26,4 +26,10 @@ var Cmd = &cobra.Command{
 func init() {
 	Cmd.AddCommand(cmdInit())
 	Cmd.AddCommand(cmdTidy())
+	Cmd.AddCommand(cmdDeps())
+	Cmd.AddCommand(cmdVendor())
+	Cmd.AddCommand(cmdUpgrade())
+	Cmd.AddCommand(cmdGet())
+	Cmd.AddCommand(cmdGraph())
+	Cmd.AddCommand(cmdWhy())
 }
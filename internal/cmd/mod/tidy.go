@@ -15,29 +15,31 @@
 package mod
 
 import (
-	"github.com/gx-org/ccgx/internal/gotc"
+	"github.com/gx-org/ccgx/internal/cmd/workspace"
 	"github.com/gx-org/ccgx/internal/gxtc"
-	"github.com/gx-org/gx/build/module"
 	"github.com/spf13/cobra"
 )
 
 func cmdTidy() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "tidy",
 		Short: "update gx.mod",
 		RunE:  cTidy,
 	}
+	workspace.AddModulesFlag(cmd)
+	workspace.AddKeepGoingFlag(cmd)
+	return cmd
 }
 
 func cTidy(cmd *cobra.Command, args []string) error {
-	mod, err := module.Current()
+	ws, err := workspace.Current()
 	if err != nil {
 		return err
 	}
-	if err := gxtc.PackAll(mod); err != nil {
+	if err := gxtc.PackAll(ws); err != nil {
 		return err
 	}
-	if err := gotc.ModTidy(); err != nil {
+	if err := gxtc.ModTidy(ws); err != nil {
 		return err
 	}
 	return nil
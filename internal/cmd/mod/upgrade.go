@@ -0,0 +1,278 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mod
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/gx-org/ccgx/internal/cmd/bind"
+	"github.com/gx-org/ccgx/internal/cmd/link"
+	"github.com/gx-org/ccgx/internal/cmd/version"
+	"github.com/gx-org/ccgx/internal/cmd/workspace"
+	"github.com/gx-org/ccgx/internal/gotc"
+	"github.com/gx-org/ccgx/internal/gxtc"
+	gxmodule "github.com/gx-org/gx/build/module"
+	"github.com/spf13/cobra"
+)
+
+var (
+	upgradeGXVersion      string
+	upgradeBackendVersion string
+	upgradeCCGXVersion    string
+	upgradeCMake          bool
+)
+
+func cmdUpgrade() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "upgrade",
+		Short: "Upgrade GX and its backend, then pack and bind again",
+		RunE:  cUpgrade,
+		Args:  cobra.NoArgs,
+	}
+	cmd.Flags().StringVarP(&upgradeGXVersion, "gx-version", "", version.Query(version.GXPath), "version of "+version.GXPath+" to require")
+	cmd.Flags().StringVarP(&upgradeBackendVersion, "backend-version", "", version.Query(version.XLAPJRTPath), "version of "+version.XLAPJRTPath+" to require")
+	cmd.Flags().StringVarP(&upgradeCCGXVersion, "ccgx-version", "", version.Query(version.CCGXPath), "version of the ccgx tool to require if ccgx is a tool of the module")
+	cmd.Flags().BoolVarP(&upgradeCMake, "cmake", "", false, "generate CMakeLists.txt")
+	link.AddModeFlag(cmd)
+	return cmd
+}
+
+func cUpgrade(cmd *cobra.Command, args []string) error {
+	ws, err := workspace.Current()
+	if err != nil {
+		return err
+	}
+	restore, err := saveModFiles(ws)
+	if err != nil {
+		return err
+	}
+	if err := upgrade(cmd.OutOrStdout(), ws); err != nil {
+		return errors.Join(err, restore())
+	}
+	return nil
+}
+
+func upgrade(w io.Writer, ws *gxtc.Workspace) error {
+	before, err := gxtc.Headers(ws)
+	if err != nil {
+		return err
+	}
+	for _, mod := range ws.Modules {
+		if err := upgradeModule(mod); err != nil {
+			return err
+		}
+	}
+	if ws, err = workspace.Reload(ws); err != nil {
+		return err
+	}
+	if err := gxtc.PackAll(ws); err != nil {
+		return err
+	}
+	if err := gxtc.ModTidy(ws); err != nil {
+		return err
+	}
+	if err := rebind(ws); err != nil {
+		return err
+	}
+	after, err := gxtc.Headers(ws)
+	if err != nil {
+		return err
+	}
+	reportHeaderChanges(w, ws.Main.Root(), before, after)
+	return nil
+}
+
+// upgradeModule requires new GX and backend versions in the go.mod file of a module.
+// Returns an error if the new versions are not compatible with ccgx.
+func upgradeModule(mod *gxmodule.Module) error {
+	if pinArgs := pins(upgradeGXVersion, upgradeBackendVersion); len(pinArgs) > 0 {
+		if err := gotc.Get(mod.Root(), pinArgs...); err != nil {
+			return err
+		}
+	}
+	if version.IsTool(mod) {
+		// The tool is compiled with the GX version of the module.
+		return gotc.Get(mod.Root(), "-tool", version.CCGXPath+"@"+upgradeCCGXVersion)
+	}
+	upgraded, err := gxmodule.New(mod.Root())
+	if err != nil {
+		return err
+	}
+	if err := version.Check(upgraded); err != nil {
+		return fmt.Errorf("cannot upgrade %s: %v", mod.Name(), err)
+	}
+	return nil
+}
+
+// saveModFiles saves the go.mod and go.sum files of all the modules of a
+// workspace and its go.work and go.work.sum files.
+// Returns a function restoring the files.
+func saveModFiles(ws *gxtc.Workspace) (func() error, error) {
+	var restores []func() error
+	for _, mod := range ws.Members() {
+		restore, err := saveFiles(mod.Root(), "go.mod", "go.sum")
+		if err != nil {
+			return nil, err
+		}
+		restores = append(restores, restore)
+	}
+	if ws.WorkFile != "" {
+		name := filepath.Base(ws.WorkFile)
+		restore, err := saveFiles(filepath.Dir(ws.WorkFile), name, name+".sum")
+		if err != nil {
+			return nil, err
+		}
+		restores = append(restores, restore)
+	}
+	return func() error {
+		var errs []error
+		for _, restore := range restores {
+			errs = append(errs, restore())
+		}
+		return errors.Join(errs...)
+	}, nil
+}
+
+// saveFiles saves the content of files in a folder.
+// Returns a function restoring the files.
+func saveFiles(dir string, names ...string) (func() error, error) {
+	saved := make(map[string][]byte)
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		saved[path] = data
+	}
+	return func() error {
+		var errs []error
+		for path, data := range saved {
+			errs = append(errs, os.WriteFile(path, data, 0644))
+		}
+		return errors.Join(errs...)
+	}, nil
+}
+
+// rebind binds the packages of a workspace with its ccgx tool if declared,
+// that is with the upgraded GX version, or with the current binary otherwise.
+func rebind(ws *gxtc.Workspace) error {
+	if !version.IsTool(ws.Main) {
+		return bind.Run(ws, upgradeCMake)
+	}
+	args := []string{"bind", "--link-mode", link.Mode.String()}
+	if upgradeCMake {
+		args = append(args, "--cmake")
+	}
+	return gotc.RunTool(ws.Main.Root(), "ccgx", args...)
+}
+
+// reportHeaderChanges prints the lines added to and removed from
+// the generated C++ headers.
+func reportHeaderChanges(w io.Writer, root string, before, after map[string][]byte) {
+	var paths []string
+	for path := range before {
+		paths = append(paths, path)
+	}
+	for path := range after {
+		if _, ok := before[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	slices.Sort(paths)
+	changed := false
+	for _, path := range paths {
+		old, hasOld := before[path]
+		cur, hasCur := after[path]
+		if hasOld && hasCur && bytes.Equal(old, cur) {
+			continue
+		}
+		changed = true
+		name, err := filepath.Rel(root, path)
+		if err != nil {
+			name = path
+		}
+		switch {
+		case !hasOld:
+			fmt.Fprintf(w, "%s: new header\n", name)
+		case !hasCur:
+			fmt.Fprintf(w, "%s: removed\n", name)
+		default:
+			fmt.Fprintf(w, "%s:\n", name)
+			for _, line := range diffLines(old, cur) {
+				fmt.Fprintf(w, "  %s\n", line)
+			}
+		}
+	}
+	if !changed {
+		fmt.Fprintln(w, "No API change in the generated headers.")
+	}
+}
+
+// diffLines returns the non-empty lines removed (prefixed with -)
+// and added (prefixed with +) between two versions of a file.
+func diffLines(old, cur []byte) []string {
+	a, b := nonEmptyLines(old), nonEmptyLines(cur)
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	var diff []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			diff = append(diff, "- "+a[i])
+			i++
+		default:
+			diff = append(diff, "+ "+b[j])
+			j++
+		}
+	}
+	return diff
+}
+
+func nonEmptyLines(data []byte) []string {
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -16,24 +16,30 @@
 package pack
 
 import (
+	"github.com/gx-org/ccgx/internal/cmd/workspace"
 	"github.com/gx-org/ccgx/internal/gxtc"
-	gxmodule "github.com/gx-org/gx/build/module"
 	"github.com/spf13/cobra"
 )
 
 // Cmd is the implementation of the mod command.
 func Cmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "pack",
+	cmd := &cobra.Command{
+		Use:   "pack [packages]",
 		Short: "Create go packages to store GX files",
 		RunE:  cPack,
 	}
+	workspace.AddModulesFlag(cmd)
+	workspace.AddKeepGoingFlag(cmd)
+	return cmd
 }
 
 func cPack(cmd *cobra.Command, args []string) error {
-	mod, err := gxmodule.Current()
+	ws, err := workspace.Select(args)
 	if err != nil {
 		return err
 	}
-	return gxtc.PackAll(mod)
+	if err := gxtc.CheckImports(ws); err != nil {
+		return err
+	}
+	return gxtc.PackAll(ws)
 }
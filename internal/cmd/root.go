@@ -15,32 +15,150 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
 	"github.com/gx-org/ccgx/internal/cmd/bind"
 	"github.com/gx-org/ccgx/internal/cmd/carchive"
 	"github.com/gx-org/ccgx/internal/cmd/debug"
+	"github.com/gx-org/ccgx/internal/cmd/doctor"
 	"github.com/gx-org/ccgx/internal/cmd/link"
+	"github.com/gx-org/ccgx/internal/cmd/list"
 	"github.com/gx-org/ccgx/internal/cmd/mod"
 	"github.com/gx-org/ccgx/internal/cmd/pack"
+	"github.com/gx-org/ccgx/internal/cmd/version"
+	"github.com/gx-org/ccgx/internal/cmd/workspace"
+	"github.com/gx-org/ccgx/internal/config"
+	"github.com/gx-org/ccgx/internal/diag"
+	"github.com/gx-org/ccgx/internal/gotc"
+	gxmodule "github.com/gx-org/gx/build/module"
 	"github.com/spf13/cobra"
 )
 
 var rootCmd = &cobra.Command{
-	Use:           "ccgx",
-	Short:         "Generate C++ bindings for GX.",
-	SilenceUsage:  true,
-	SilenceErrors: false,
+	Use:          "ccgx",
+	Short:        "Generate C++ bindings for GX.",
+	SilenceUsage: true,
+	// Errors are reported by Execute in the selected format.
+	SilenceErrors: true,
+
+	PersistentPreRunE: loadConfig,
+}
+
+// format is the format in which errors are reported.
+var format = diag.FormatText
+
+// goBinary is the go binary selected on the command line.
+var goBinary string
+
+// loadConfig reads the configuration file of the current module, if any,
+// and checks the Go toolchain.
+func loadConfig(cmd *cobra.Command, args []string) error {
+	if err := applyConfig(cmd); err != nil {
+		return err
+	}
+	if goBinary != "" {
+		gotc.Go = gotc.Binary(goBinary)
+	}
+	if _, noCheck := cmd.Annotations[gotc.NoCheck]; noCheck {
+		return nil
+	}
+	return gotc.Check()
+}
+
+// applyConfig applies the configuration file of the current module, if any.
+// Command line flags take precedence over the configuration file.
+func applyConfig(cmd *cobra.Command) error {
+	// gxmodule.Current is not used because it caches its result
+	// and the module may not exist yet (e.g. ccgx mod init).
+	mod, err := gxmodule.New("")
+	if err != nil {
+		return nil
+	}
+	cfg, err := config.Load(mod.Root())
+	if err != nil {
+		return err
+	}
+	if !cmd.Flags().Changed("go") {
+		goBinary = cfg.Go
+	}
+	gotc.GoToolchain = cfg.Toolchain
+	workspace.Targets = cfg.Targets
+	if !cmd.Flags().Changed("timeout") && cfg.Timeout != "" {
+		if gotc.Timeout, err = time.ParseDuration(cfg.Timeout); err != nil {
+			return fmt.Errorf("%s: invalid timeout: %v", config.FileName, err)
+		}
+	}
+	if !cmd.Flags().Changed("offline") {
+		gotc.Offline = cfg.Offline
+	}
+	if !cmd.Flags().Changed("link-mode") && cfg.LinkMode != "" {
+		if err := link.Mode.Set(cfg.LinkMode); err != nil {
+			return fmt.Errorf("%s: %v", config.FileName, err)
+		}
+	}
+	return nil
 }
 
 // Execute executes the root command.
 func Execute() error {
-	return rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		// Restore the default behavior so that a second signal stops ccgx immediately.
+		<-ctx.Done()
+		stop()
+	}()
+	gotc.Context = ctx
+	err := rootCmd.ExecuteContext(ctx)
+	if errors.Is(err, version.ErrReexecuted) {
+		// The result has been reported by the ccgx which ran the command.
+		return writeTrace(err)
+	}
+	out := os.Stdout
+	if format == diag.FormatText {
+		out = os.Stderr
+	}
+	if writeErr := diag.Write(out, format, err); writeErr != nil && err == nil {
+		err = writeErr
+	}
+	if format != diag.FormatText && err != nil {
+		// The report on stdout is read by tools: also tell the user.
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	}
+	return writeTrace(err)
+}
+
+// writeTrace writes the trace file, if any, and returns the error
+// of the command or, if it succeeded, the error writing the trace.
+func writeTrace(err error) error {
+	if traceErr := debug.WriteTrace(); traceErr != nil {
+		fmt.Fprintln(os.Stderr, traceErr)
+		if err == nil {
+			err = traceErr
+		}
+	}
+	return err
 }
 
 func init() {
 	rootCmd.PersistentFlags().BoolVarP(&debug.Debug, "debug", "d", false, "print debug information")
+	rootCmd.PersistentFlags().VarP(&format, "format", "", "format of the errors: text, json, or sarif")
+	rootCmd.PersistentFlags().StringVarP(&debug.TraceFile, "trace", "", "", "write a trace of all the operations in a file in the Chrome trace event format")
+	rootCmd.PersistentFlags().StringVarP(&goBinary, "go", "", "", "go binary used to run the Go toolchain (default go from the PATH)")
+	rootCmd.PersistentFlags().DurationVarP(&gotc.Timeout, "timeout", "", 0, "maximum duration of a go command (default no timeout)")
+	rootCmd.PersistentFlags().BoolVarP(&gotc.Offline, "offline", "", false, "prevent the Go toolchain from accessing the network")
 	rootCmd.AddCommand(mod.Cmd)
 	rootCmd.AddCommand(link.Cmd())
 	rootCmd.AddCommand(bind.Cmd())
 	rootCmd.AddCommand(carchive.Cmd())
 	rootCmd.AddCommand(pack.Cmd())
+	rootCmd.AddCommand(list.Cmd())
+	rootCmd.AddCommand(doctor.Cmd())
+	rootCmd.AddCommand(version.Cmd())
 }
@@ -0,0 +1,219 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package version provides the Cobra version command.
+// It also checks that the GX modules ccgx has been compiled with are
+// compatible with the GX modules required by a project: the bindings are
+// generated by the GX version of ccgx while the runtime is provided by
+// the GX version of the project.
+package version
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gx-org/ccgx/internal/gotc"
+	gxmodule "github.com/gx-org/gx/build/module"
+	"github.com/spf13/cobra"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// Paths of the modules reported by the version command.
+const (
+	CCGXPath    = "github.com/gx-org/ccgx"
+	GXPath      = "github.com/gx-org/gx"
+	BackendPath = "github.com/gx-org/backend"
+	XLAPJRTPath = "github.com/gx-org/xlapjrt"
+)
+
+// checkedPaths are the modules which need to have compatible versions
+// between ccgx and the project.
+var checkedPaths = []string{GXPath, BackendPath}
+
+// Cmd is the implementation of the version command.
+func Cmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "Print the versions of ccgx, GX, and the backend",
+		RunE:  cVersion,
+		Args:  cobra.NoArgs,
+		// The version of ccgx does not depend on Go.
+		Annotations: map[string]string{gotc.NoCheck: ""},
+	}
+}
+
+// CCGX returns the versions of the modules ccgx has been compiled with,
+// including ccgx itself.
+func CCGX() map[string]string {
+	versions := make(map[string]string)
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return versions
+	}
+	versions[CCGXPath] = info.Main.Version
+	for _, dep := range info.Deps {
+		if dep.Replace != nil {
+			dep = dep.Replace
+		}
+		versions[dep.Path] = dep.Version
+	}
+	return versions
+}
+
+// Project returns the versions of the modules required by a project.
+func Project(mod *gxmodule.Module) map[string]string {
+	versions := make(map[string]string)
+	for _, req := range mod.File().Require {
+		versions[req.Mod.Path] = req.Mod.Version
+	}
+	for _, rep := range mod.File().Replace {
+		if _, ok := versions[rep.Old.Path]; !ok {
+			continue
+		}
+		if rep.New.Version == "" {
+			versions[rep.Old.Path] = rep.New.Path
+			continue
+		}
+		versions[rep.Old.Path] = rep.New.Version
+	}
+	return versions
+}
+
+func cVersion(cmd *cobra.Command, args []string) error {
+	ccgx := CCGX()
+	var project map[string]string
+	if mod, err := gxmodule.New(""); err == nil {
+		project = Project(mod)
+	}
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+	if project == nil {
+		fmt.Fprintln(w, "MODULE\tCCGX")
+	} else {
+		fmt.Fprintln(w, "MODULE\tCCGX\tPROJECT")
+	}
+	for _, path := range []string{CCGXPath, GXPath, BackendPath, XLAPJRTPath} {
+		if project == nil {
+			fmt.Fprintf(w, "%s\t%s\n", path, ccgx[path])
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", path, ccgx[path], project[path])
+	}
+	return w.Flush()
+}
+
+// compatible returns true if two versions of a module are compatible.
+// Versions are compatible if they have the same major and minor versions.
+// GX is still at major version 0 so any minor release can break its API.
+func compatible(a, b string) bool {
+	if !semver.IsValid(a) || !semver.IsValid(b) {
+		// Pseudo-versions of local replacements cannot be compared.
+		return true
+	}
+	return semver.MajorMinor(a) == semver.MajorMinor(b)
+}
+
+// Query returns the version query selecting the newest version of a module
+// compatible with ccgx, that is with the same major and minor versions as
+// the version ccgx has been compiled with (e.g. v0.6). Returns "latest" if
+// ccgx has not been compiled with a release of the module.
+func Query(path string) string {
+	return minorQuery(CCGX()[path])
+}
+
+// minorQuery returns the version query selecting the newest version with
+// the same major and minor versions as v, or "latest" if v is not a release.
+func minorQuery(v string) string {
+	if !semver.IsValid(v) || module.IsPseudoVersion(v) {
+		return "latest"
+	}
+	return semver.MajorMinor(v)
+}
+
+// Check returns an error if the modules ccgx has been compiled with are not
+// compatible with the modules required by a project. A warning is printed if
+// the versions are compatible but differ.
+func Check(mod *gxmodule.Module) error {
+	ccgx := CCGX()
+	project := Project(mod)
+	var errs []string
+	for _, path := range checkedPaths {
+		ccgxVersion, projectVersion := ccgx[path], project[path]
+		if ccgxVersion == "" || projectVersion == "" || ccgxVersion == projectVersion {
+			continue
+		}
+		if compatible(ccgxVersion, projectVersion) {
+			log.Printf("WARNING: ccgx has been compiled with %s %s but %s requires %s", path, ccgxVersion, mod.Name(), projectVersion)
+			continue
+		}
+		errs = append(errs, fmt.Sprintf("%s: ccgx has been compiled with %s but %s requires %s", path, ccgxVersion, mod.Name(), projectVersion))
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("ccgx is not compatible with the GX version of %s:\n\t%s\n"+
+		"Add ccgx as a tool of the module to compile it with the GX version of the module:\n"+
+		"\tgo get -tool %s\n"+
+		"then run `go tool ccgx` instead of `ccgx` (or use the --reexec flag to run a compatible ccgx)",
+		mod.Name(), strings.Join(errs, "\n\t"), CCGXPath)
+}
+
+// IsTool returns true if ccgx is declared as a tool in the go.mod file of a module.
+func IsTool(mod *gxmodule.Module) bool {
+	return slices.ContainsFunc(mod.File().Tool, func(tool *modfile.Tool) bool {
+		return tool.Path == CCGXPath
+	})
+}
+
+const reexecKey = "CCGX_REEXEC"
+
+// ErrReexecuted is returned by a command which has been run by another ccgx
+// binary, compiled with the GX version of the module. That binary has
+// already reported the result of the command.
+var ErrReexecuted = errors.New("command run by a ccgx compatible with the module")
+
+// Reexec runs the current command with a ccgx compiled with the GX version
+// required by a module: the ccgx tool declared in the go.mod file of the
+// module or, if ccgx is not a tool of the module, the newest ccgx with the
+// same major and minor versions as GX (e.g. go run github.com/gx-org/ccgx@v0.6).
+// Returns nil if the command is already run by another ccgx. Otherwise, the
+// returned error wraps ErrReexecuted.
+func Reexec(mod *gxmodule.Module) error {
+	if os.Getenv(reexecKey) != "" {
+		// Already running as a compatible ccgx.
+		return nil
+	}
+	args := slices.DeleteFunc(slices.Clone(os.Args[1:]), func(arg string) bool {
+		return arg == "--reexec"
+	})
+	os.Setenv(reexecKey, "1")
+	var err error
+	if IsTool(mod) {
+		err = gotc.RunTool(mod.Root(), "ccgx", args...)
+	} else {
+		// ccgx and GX share major and minor versions.
+		err = gotc.RunPackage(CCGXPath+"@"+minorQuery(Project(mod)[GXPath]), args...)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrReexecuted, err)
+	}
+	return ErrReexecuted
+}
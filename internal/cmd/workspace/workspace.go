@@ -0,0 +1,122 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package workspace provides the workspace processed by the commands.
+package workspace
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/gx-org/ccgx/internal/config"
+	"github.com/gx-org/ccgx/internal/gxtc"
+	gxmodule "github.com/gx-org/gx/build/module"
+	"github.com/spf13/cobra"
+)
+
+// Modules selects the modules of a Go workspace to process.
+// All the modules of the workspace are processed if empty.
+var Modules []string
+
+// AddModulesFlag adds the flag to select the modules of a Go workspace to a command.
+func AddModulesFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringSliceVarP(&Modules, "modules", "", nil, "modules of the Go workspace to process (default all)")
+}
+
+// AddKeepGoingFlag adds the flag to process all the packages even if some fail to a command.
+func AddKeepGoingFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().BoolVarP(&gxtc.KeepGoing, "keep-going", "k", false, "process all the packages even if some fail, then report all the failures")
+}
+
+// Targets are the C archives defined in the configuration of the module.
+var Targets map[string]config.Target
+
+// TargetNames selects the targets to build. All the targets are built if empty.
+var TargetNames []string
+
+// AddTargetFlag adds the flag to select the targets to build to a command.
+func AddTargetFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringSliceVarP(&TargetNames, "target", "", nil, "C archive targets of "+config.FileName+" to build (default all)")
+}
+
+// Current returns the workspace of the current module.
+func Current() (*gxtc.Workspace, error) {
+	ws, err := gxtc.CurrentWorkspace(Modules)
+	if err != nil {
+		return nil, err
+	}
+	if err := addTargets(ws); err != nil {
+		return nil, err
+	}
+	return ws, nil
+}
+
+// addTargets adds the selected targets of the configuration to a workspace.
+func addTargets(ws *gxtc.Workspace) error {
+	for _, name := range TargetNames {
+		if _, ok := Targets[name]; !ok {
+			return fmt.Errorf("target %s not defined in %s", name, config.FileName)
+		}
+	}
+	names := make([]string, 0, len(Targets))
+	for name := range Targets {
+		if len(TargetNames) == 0 || slices.Contains(TargetNames, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		target := Targets[name]
+		if err := ws.AddTarget(name, target.Packages, target.Backend); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Select returns the current workspace restricted to the GX packages
+// matching patterns and their transitive GX imports.
+func Select(patterns []string) (*gxtc.Workspace, error) {
+	ws, err := gxtc.CurrentWorkspace(Modules)
+	if err != nil {
+		return nil, err
+	}
+	if err := ws.Select(patterns); err != nil {
+		return nil, err
+	}
+	// Targets are added last to bind their dependency packages too.
+	if err := addTargets(ws); err != nil {
+		return nil, err
+	}
+	return ws, nil
+}
+
+// Reload reads the go.mod files of a workspace again,
+// for example after its requirements have been modified.
+func Reload(ws *gxtc.Workspace) (*gxtc.Workspace, error) {
+	mod, err := gxmodule.New(ws.Main.Root())
+	if err != nil {
+		return nil, err
+	}
+	reloaded, err := gxtc.NewWorkspace(mod, Modules)
+	if err != nil {
+		return nil, err
+	}
+	if err := addTargets(reloaded); err != nil {
+		return nil, err
+	}
+	reloaded.Require = ws.Require
+	return reloaded, nil
+}
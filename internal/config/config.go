@@ -0,0 +1,72 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package config reads the ccgx configuration file of a module.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FileName is the name of the configuration file at the root of a module.
+const FileName = "ccgx.json"
+
+// Config is the ccgx configuration of a module.
+type Config struct {
+	// Offline prevents the Go toolchain from accessing the network.
+	Offline bool `json:"offline"`
+	// LinkMode specifies how dependencies are installed in gxdeps:
+	// absolute (default), relative, or copy.
+	LinkMode string `json:"linkMode"`
+	// Go is the go binary used to run the Go toolchain.
+	Go string `json:"go"`
+	// Toolchain is the value of GOTOOLCHAIN if not set in the environment.
+	Toolchain string `json:"toolchain"`
+	// Timeout is the maximum duration of a go command (e.g. "10m").
+	Timeout string `json:"timeout"`
+	// Targets are named C archives, each built in gxdeps/<name>
+	// from a subset of the GX packages of the module.
+	Targets map[string]Target `json:"targets"`
+}
+
+// Target is a C archive built from a subset of the GX packages.
+type Target struct {
+	// Packages are the patterns selecting the GX packages of the target,
+	// relative to the root of the module (e.g. "./models/...").
+	Packages []string `json:"packages"`
+	// Backend is the Go package providing the GX runtime
+	// (default github.com/gx-org/xlapjrt/cgx).
+	Backend string `json:"backend"`
+}
+
+// Load reads the configuration file at the root of a module.
+// Returns an empty configuration if the file does not exist.
+func Load(root string) (*Config, error) {
+	cfg := &Config{}
+	path := filepath.Join(root, FileName)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("cannot parse %s: %v", path, err)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,201 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package diag parses GX and Go errors into diagnostics attached to
+// a position in a source file and prints them as text, JSON, or SARIF.
+package diag
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gx-org/gx/build/fmterr"
+)
+
+// Severity of a diagnostic.
+type Severity string
+
+const (
+	// SeverityError is an error preventing the build.
+	SeverityError Severity = "error"
+	// SeverityWarning is a warning.
+	SeverityWarning Severity = "warning"
+	// SeverityNote is additional information about a previous diagnostic.
+	SeverityNote Severity = "note"
+)
+
+// Diagnostic is a message attached to a position in a source file.
+type Diagnostic struct {
+	// File is the absolute path of the source file.
+	File string `json:"file"`
+	// Line is the line in the file, starting at 1.
+	Line int `json:"line"`
+	// Column is the column in the line, starting at 1. Zero if unknown.
+	Column int `json:"column,omitempty"`
+	// Severity of the diagnostic.
+	Severity Severity `json:"severity"`
+	// Message describes the problem.
+	Message string `json:"message"`
+	// Tool is the tool which reported the diagnostic: gx, go, or cc.
+	Tool string `json:"tool"`
+}
+
+// String returns the diagnostic in the format used by compilers.
+// The path of the file is relative to the current folder when possible.
+func (d Diagnostic) String() string {
+	pos := fmt.Sprintf("%s:%d", RelPath(d.File), d.Line)
+	if d.Column > 0 {
+		pos += fmt.Sprintf(":%d", d.Column)
+	}
+	return fmt.Sprintf("%s: %s: %s", pos, d.Severity, d.Message)
+}
+
+// RelPath returns a path relative to the current folder if the path
+// is in the current folder. Returns the path unchanged otherwise.
+func RelPath(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(wd, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return rel
+}
+
+// Error is an error with diagnostics.
+type Error struct {
+	// Msg summarizes the error.
+	Msg string
+	// Diagnostics are the problems found in source files.
+	Diagnostics []Diagnostic
+	// Output is the raw output of the tool which failed.
+	// It is only reported if no diagnostic has been parsed from it.
+	Output string
+}
+
+// Error returns the summary followed by the diagnostics.
+func (err *Error) Error() string {
+	lines := []string{err.Msg}
+	for _, d := range err.Diagnostics {
+		lines = append(lines, d.String())
+	}
+	if len(err.Diagnostics) == 0 && err.Output != "" {
+		lines = append(lines, err.Output)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Collect returns all the diagnostics of the errors in the tree of err.
+func Collect(err error) []Diagnostic {
+	if err == nil {
+		return nil
+	}
+	if dErr, ok := err.(*Error); ok {
+		return dErr.Diagnostics
+	}
+	var diags []Diagnostic
+	switch x := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, e := range x.Unwrap() {
+			diags = append(diags, Collect(e)...)
+		}
+	case interface{ Unwrap() error }:
+		diags = Collect(x.Unwrap())
+	}
+	return diags
+}
+
+// FromGX returns the diagnostics of an error returned by the GX builder.
+// resolve returns the absolute path of a file as named by the GX builder.
+func FromGX(err error, resolve func(string) string) []Diagnostic {
+	var gxErrs *fmterr.Errors
+	if errors.As(err, &gxErrs) {
+		var diags []Diagnostic
+		for _, e := range gxErrs.Errors() {
+			diags = append(diags, FromGX(e, resolve)...)
+		}
+		return diags
+	}
+	var posErr fmterr.ErrorWithPos
+	if !errors.As(err, &posErr) || posErr.FSet() == nil || posErr.Src() == nil {
+		return nil
+	}
+	pos := posErr.FSet().Position(posErr.Src().Pos())
+	if !pos.IsValid() {
+		return nil
+	}
+	return []Diagnostic{{
+		File:     resolve(pos.Filename),
+		Line:     pos.Line,
+		Column:   pos.Column,
+		Severity: SeverityError,
+		Message:  posErr.Err().Error(),
+		Tool:     "gx",
+	}}
+}
+
+// compilerLine matches the lines printed by the Go and C/C++ compilers:
+// file:line[:column]: message
+var compilerLine = regexp.MustCompile(`^([^\s:][^:]*):(\d+)(?::(\d+))?: (.*)$`)
+
+// Parse returns the diagnostics printed by the Go toolchain or a C/C++
+// compiler run in dir. Lines which do not refer to an existing file are
+// ignored. Indented lines following a diagnostic are appended to it.
+func Parse(dir, output string) []Diagnostic {
+	var diags []Diagnostic
+	last := -1
+	for _, line := range strings.Split(output, "\n") {
+		if last >= 0 && strings.HasPrefix(line, "\t") {
+			diags[last].Message += "\n" + strings.TrimSpace(line)
+			continue
+		}
+		last = -1
+		m := compilerLine.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		file := m[1]
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(dir, file)
+		}
+		if abs, err := filepath.Abs(file); err == nil {
+			file = abs
+		}
+		if _, err := os.Stat(file); err != nil {
+			continue
+		}
+		d := Diagnostic{File: file, Severity: SeverityError, Message: m[4], Tool: "go"}
+		d.Line, _ = strconv.Atoi(m[2])
+		d.Column, _ = strconv.Atoi(m[3])
+		if filepath.Ext(file) != ".go" {
+			d.Tool = "cc"
+		}
+		for _, sev := range []Severity{SeverityError, SeverityWarning, SeverityNote} {
+			if msg, ok := strings.CutPrefix(d.Message, string(sev)+": "); ok {
+				d.Severity, d.Message = sev, msg
+				break
+			}
+		}
+		diags = append(diags, d)
+		last = len(diags) - 1
+	}
+	return diags
+}
@@ -0,0 +1,152 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// testDir returns a folder with the source files referred to by the outputs.
+func testDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range []string{"main.go", "gxdeps/carchive.go", "gxdeps/pkg/pkg.cc", "gxdeps/pkg/pkg.h"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestParse(t *testing.T) {
+	dir := testDir(t)
+	path := func(name string) string { return filepath.Join(dir, filepath.FromSlash(name)) }
+	tests := []struct {
+		name   string
+		output string
+		want   []Diagnostic
+	}{
+		{
+			name: "go build",
+			output: `# example.com/app
+./main.go:5:2: undefined: foo
+./main.go:6:9: cannot use x (variable of type int) as string value in return statement`,
+			want: []Diagnostic{
+				{File: path("main.go"), Line: 5, Column: 2, Severity: SeverityError, Message: "undefined: foo", Tool: "go"},
+				{File: path("main.go"), Line: 6, Column: 9, Severity: SeverityError, Message: "cannot use x (variable of type int) as string value in return statement", Tool: "go"},
+			},
+		},
+		{
+			name: "go vet continuation lines",
+			output: `main.go:7:14: cannot use f (variable of type func()) as func(int) value in argument to run
+	have func()
+	want func(int)`,
+			want: []Diagnostic{
+				{File: path("main.go"), Line: 7, Column: 14, Severity: SeverityError, Message: "cannot use f (variable of type func()) as func(int) value in argument to run\nhave func()\nwant func(int)", Tool: "go"},
+			},
+		},
+		{
+			name: "cgo",
+			output: `# command-line-arguments
+gxdeps/carchive.go:10:8: could not import example.com/app/gxdeps/packager/example.com/app/pkg (open : no such file or directory)`,
+			want: []Diagnostic{
+				{File: path("gxdeps/carchive.go"), Line: 10, Column: 8, Severity: SeverityError, Message: "could not import example.com/app/gxdeps/packager/example.com/app/pkg (open : no such file or directory)", Tool: "go"},
+			},
+		},
+		{
+			name: "gcc",
+			output: `gxdeps/pkg/pkg.cc: In function 'int main()':
+gxdeps/pkg/pkg.cc:12:5: error: 'foo' was not declared in this scope
+   12 |     foo();
+      |     ^~~
+gxdeps/pkg/pkg.h:4:7: warning: unused variable 'y' [-Wunused-variable]
+gxdeps/pkg/pkg.h:2:3: note: declared here
+compilation terminated.`,
+			want: []Diagnostic{
+				{File: path("gxdeps/pkg/pkg.cc"), Line: 12, Column: 5, Severity: SeverityError, Message: "'foo' was not declared in this scope", Tool: "cc"},
+				{File: path("gxdeps/pkg/pkg.h"), Line: 4, Column: 7, Severity: SeverityWarning, Message: "unused variable 'y' [-Wunused-variable]", Tool: "cc"},
+				{File: path("gxdeps/pkg/pkg.h"), Line: 2, Column: 3, Severity: SeverityNote, Message: "declared here", Tool: "cc"},
+			},
+		},
+		{
+			name:   "gcc fatal error",
+			output: `gxdeps/pkg/pkg.cc:3:10: fatal error: gomlx/xlabuilder.h: No such file or directory`,
+			want: []Diagnostic{
+				{File: path("gxdeps/pkg/pkg.cc"), Line: 3, Column: 10, Severity: SeverityError, Message: "fatal error: gomlx/xlabuilder.h: No such file or directory", Tool: "cc"},
+			},
+		},
+		{
+			name:   "no column",
+			output: `main.go:7: missing return`,
+			want: []Diagnostic{
+				{File: path("main.go"), Line: 7, Severity: SeverityError, Message: "missing return", Tool: "go"},
+			},
+		},
+		{
+			name: "unknown files",
+			output: `/nonexistent/main.go:1:1: undefined: foo
+go: downloading example.com/lib v1.0.0
+http://example.com:80: not a file`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := Parse(dir, test.output)
+			if !slices.Equal(got, test.want) {
+				t.Errorf("Parse() =\n%v\nwant:\n%v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCollect(t *testing.T) {
+	d1 := Diagnostic{File: "/a.go", Line: 1, Message: "one"}
+	d2 := Diagnostic{File: "/b.go", Line: 2, Message: "two"}
+	tests := []struct {
+		name string
+		err  error
+		want []Diagnostic
+	}{
+		{name: "nil"},
+		{name: "no diagnostic", err: errors.New("failed")},
+		{name: "error", err: &Error{Msg: "build", Diagnostics: []Diagnostic{d1, d2}}, want: []Diagnostic{d1, d2}},
+		{name: "wrapped", err: fmt.Errorf("bind: %w", &Error{Diagnostics: []Diagnostic{d1}}), want: []Diagnostic{d1}},
+		{
+			name: "joined",
+			err: errors.Join(
+				&Error{Diagnostics: []Diagnostic{d1}},
+				errors.New("failed"),
+				fmt.Errorf("carchive: %w", &Error{Diagnostics: []Diagnostic{d2}}),
+			),
+			want: []Diagnostic{d1, d2},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Collect(test.err); !slices.Equal(got, test.want) {
+				t.Errorf("Collect() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
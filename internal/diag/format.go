@@ -0,0 +1,206 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Format specifies how errors are reported.
+type Format string
+
+const (
+	// FormatText prints errors and diagnostics like a compiler.
+	FormatText Format = "text"
+	// FormatJSON prints errors and diagnostics as a JSON object.
+	FormatJSON Format = "json"
+	// FormatSARIF prints diagnostics as a SARIF 2.1.0 log.
+	FormatSARIF Format = "sarif"
+)
+
+var formats = []Format{FormatText, FormatJSON, FormatSARIF}
+
+// String returns the name of the format.
+func (f *Format) String() string {
+	return string(*f)
+}
+
+// Set the format from its name.
+func (f *Format) Set(s string) error {
+	if !slices.Contains(formats, Format(s)) {
+		return fmt.Errorf("invalid format %q: must be one of %v", s, formats)
+	}
+	*f = Format(s)
+	return nil
+}
+
+// Type returns the type of the flag.
+func (f *Format) Type() string {
+	return "format"
+}
+
+// Write reports the result of a command in a given format.
+// Nothing is written in text format if err is nil. In JSON and SARIF
+// formats, a report without any diagnostic is written if err is nil.
+// In SARIF format, err is reported as a notification of the invocation.
+func Write(w io.Writer, format Format, err error) error {
+	diags := Collect(err)
+	switch format {
+	case FormatJSON:
+		report := struct {
+			Error       string       `json:"error,omitempty"`
+			Diagnostics []Diagnostic `json:"diagnostics"`
+		}{Diagnostics: diags}
+		if err != nil {
+			report.Error = err.Error()
+		}
+		if report.Diagnostics == nil {
+			report.Diagnostics = []Diagnostic{}
+		}
+		return writeJSON(w, report)
+	case FormatSARIF:
+		return writeJSON(w, sarifLog(diags, err))
+	}
+	if err == nil {
+		return nil
+	}
+	_, wErr := fmt.Fprintf(w, "Error: %v\n", err)
+	return wErr
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+type (
+	sarif struct {
+		Version string     `json:"version"`
+		Schema  string     `json:"$schema"`
+		Runs    []sarifRun `json:"runs"`
+	}
+
+	sarifRun struct {
+		Tool               sarifTool                   `json:"tool"`
+		OriginalURIBaseIDs map[string]sarifArtifactLoc `json:"originalUriBaseIds,omitempty"`
+		Invocations        []sarifInvocation           `json:"invocations"`
+		Results            []sarifResult               `json:"results"`
+	}
+
+	sarifInvocation struct {
+		ExecutionSuccessful        bool                `json:"executionSuccessful"`
+		ToolExecutionNotifications []sarifNotification `json:"toolExecutionNotifications,omitempty"`
+	}
+
+	sarifNotification struct {
+		Level   string       `json:"level"`
+		Message sarifMessage `json:"message"`
+	}
+
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+
+	sarifDriver struct {
+		Name           string `json:"name"`
+		InformationURI string `json:"informationUri"`
+	}
+
+	sarifResult struct {
+		RuleID    string          `json:"ruleId"`
+		Level     string          `json:"level"`
+		Message   sarifMessage    `json:"message"`
+		Locations []sarifLocation `json:"locations"`
+	}
+
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLoc `json:"physicalLocation"`
+	}
+
+	sarifPhysicalLoc struct {
+		ArtifactLocation sarifArtifactLoc `json:"artifactLocation"`
+		Region           sarifRegion      `json:"region"`
+	}
+
+	sarifArtifactLoc struct {
+		URI       string `json:"uri"`
+		URIBaseID string `json:"uriBaseId,omitempty"`
+	}
+
+	sarifRegion struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn,omitempty"`
+	}
+)
+
+const srcRoot = "SRCROOT"
+
+func sarifLog(diags []Diagnostic, err error) *sarif {
+	invocation := sarifInvocation{ExecutionSuccessful: err == nil}
+	if err != nil {
+		invocation.ToolExecutionNotifications = []sarifNotification{{
+			Level:   string(SeverityError),
+			Message: sarifMessage{Text: err.Error()},
+		}}
+	}
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "ccgx",
+			InformationURI: "https://github.com/gx-org/ccgx",
+		}},
+		Invocations: []sarifInvocation{invocation},
+		Results:     []sarifResult{},
+	}
+	if wd, err := os.Getwd(); err == nil {
+		run.OriginalURIBaseIDs = map[string]sarifArtifactLoc{
+			srcRoot: {URI: fileURI(wd) + "/"},
+		}
+	}
+	for _, d := range diags {
+		loc := sarifArtifactLoc{URI: fileURI(d.File)}
+		if rel := RelPath(d.File); !filepath.IsAbs(rel) {
+			loc = sarifArtifactLoc{URI: filepath.ToSlash(rel), URIBaseID: srcRoot}
+		}
+		run.Results = append(run.Results, sarifResult{
+			RuleID:  d.Tool,
+			Level:   string(d.Severity),
+			Message: sarifMessage{Text: d.Message},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLoc{
+				ArtifactLocation: loc,
+				Region:           sarifRegion{StartLine: d.Line, StartColumn: d.Column},
+			}}},
+		})
+	}
+	return &sarif{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs:    []sarifRun{run},
+	}
+}
+
+func fileURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
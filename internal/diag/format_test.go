@@ -0,0 +1,192 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "success"},
+		{name: "error", err: errors.New("failed"), want: "Error: failed\n"},
+		{
+			name: "diagnostics",
+			err: &Error{Msg: "cannot build", Diagnostics: []Diagnostic{
+				{File: "/src/a.go", Line: 3, Column: 2, Severity: SeverityError, Message: "undefined: x"},
+			}},
+			want: "Error: cannot build\n/src/a.go:3:2: error: undefined: x\n",
+		},
+		{
+			name: "output",
+			err:  &Error{Msg: "go build: exit status 1", Output: "go: no Go files"},
+			want: "Error: go build: exit status 1\ngo: no Go files\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := Write(&b, FormatText, test.err); err != nil {
+				t.Fatal(err)
+			}
+			if got := b.String(); got != test.want {
+				t.Errorf("Write() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	d := Diagnostic{File: "/src/a.go", Line: 3, Column: 2, Severity: SeverityError, Message: "undefined: x", Tool: "go"}
+	tests := []struct {
+		name      string
+		err       error
+		wantError string
+		wantDiags int
+	}{
+		{name: "success"},
+		{name: "error", err: errors.New("failed"), wantError: "failed"},
+		{
+			name:      "diagnostics",
+			err:       &Error{Msg: "cannot build", Diagnostics: []Diagnostic{d}},
+			wantError: "cannot build\n/src/a.go:3:2: error: undefined: x",
+			wantDiags: 1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := Write(&b, FormatJSON, test.err); err != nil {
+				t.Fatal(err)
+			}
+			var got struct {
+				Error       *string      `json:"error"`
+				Diagnostics []Diagnostic `json:"diagnostics"`
+			}
+			if err := json.Unmarshal([]byte(b.String()), &got); err != nil {
+				t.Fatalf("cannot parse %s: %v", b.String(), err)
+			}
+			gotError := ""
+			if got.Error != nil {
+				gotError = *got.Error
+			}
+			if gotError != test.wantError {
+				t.Errorf("error = %q, want %q", gotError, test.wantError)
+			}
+			if got.Diagnostics == nil || len(got.Diagnostics) != test.wantDiags {
+				t.Errorf("diagnostics = %v, want %d diagnostics", got.Diagnostics, test.wantDiags)
+			}
+			if test.wantDiags > 0 && got.Diagnostics[0] != d {
+				t.Errorf("diagnostic = %v, want %v", got.Diagnostics[0], d)
+			}
+		})
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	local := Diagnostic{File: filepath.Join(wd, "a.go"), Line: 3, Column: 2, Severity: SeverityError, Message: "undefined: x", Tool: "go"}
+	outside := Diagnostic{File: "/outside/b.h", Line: 4, Severity: SeverityWarning, Message: "unused", Tool: "cc"}
+	type sarifJSON struct {
+		Runs []struct {
+			Invocations []struct {
+				ExecutionSuccessful        bool
+				ToolExecutionNotifications []struct {
+					Level   string
+					Message struct{ Text string }
+				}
+			}
+			Results []struct {
+				RuleID    string
+				Level     string
+				Message   struct{ Text string }
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI       string
+							URIBaseID string
+						}
+						Region struct{ StartLine, StartColumn int }
+					}
+				}
+			}
+		}
+	}
+	tests := []struct {
+		name        string
+		err         error
+		wantSuccess bool
+		wantNotes   []string
+		wantURIs    []string
+	}{
+		{name: "success", wantSuccess: true},
+		{name: "error without diagnostic", err: errors.New("module file go.mod not found"), wantNotes: []string{"module file go.mod not found"}},
+		{
+			name:      "diagnostics",
+			err:       &Error{Msg: "cannot build", Diagnostics: []Diagnostic{local, outside}},
+			wantNotes: []string{(&Error{Msg: "cannot build", Diagnostics: []Diagnostic{local, outside}}).Error()},
+			wantURIs:  []string{"a.go", "file:///outside/b.h"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := Write(&b, FormatSARIF, test.err); err != nil {
+				t.Fatal(err)
+			}
+			var got sarifJSON
+			if err := json.Unmarshal([]byte(b.String()), &got); err != nil {
+				t.Fatalf("cannot parse %s: %v", b.String(), err)
+			}
+			if len(got.Runs) != 1 || len(got.Runs[0].Invocations) != 1 {
+				t.Fatalf("want one run with one invocation, got:\n%s", b.String())
+			}
+			run := got.Runs[0]
+			inv := run.Invocations[0]
+			if inv.ExecutionSuccessful != test.wantSuccess {
+				t.Errorf("executionSuccessful = %v, want %v", inv.ExecutionSuccessful, test.wantSuccess)
+			}
+			var notes []string
+			for _, n := range inv.ToolExecutionNotifications {
+				if n.Level != "error" {
+					t.Errorf("notification level = %q, want error", n.Level)
+				}
+				notes = append(notes, n.Message.Text)
+			}
+			if strings.Join(notes, "|") != strings.Join(test.wantNotes, "|") {
+				t.Errorf("notifications = %q, want %q", notes, test.wantNotes)
+			}
+			var uris []string
+			for _, r := range run.Results {
+				uris = append(uris, r.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+			}
+			if strings.Join(uris, "|") != strings.Join(test.wantURIs, "|") {
+				t.Errorf("result URIs = %q, want %q", uris, test.wantURIs)
+			}
+		})
+	}
+}
@@ -2,18 +2,75 @@
 package exec
 
 import (
-	"log"
+	"bytes"
+	"context"
+	"os"
 	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/gx-org/ccgx/internal/cmd/debug"
 )
 
-// Command creates a new command.
-func Command(name string, args ...string) *exec.Cmd {
-	if debug.Debug {
-		cmdS := append([]string{"DEBUG", "go"}, args...)
-		log.Println(strings.Join(cmdS, " "))
+// CommandContext creates a new command killed, with all its children,
+// when the context is done.
+func CommandContext(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	killGroup(cmd)
+	// Do not wait forever for children holding the output pipes.
+	cmd.WaitDelay = 5 * time.Second
+	return cmd
+}
+
+// LookPath searches for an executable in the PATH.
+func LookPath(file string) (string, error) {
+	return exec.LookPath(file)
+}
+
+// tracedEnv are the environment variables reported when a command is traced.
+var tracedEnv = []string{
+	"GOFLAGS", "GOPROXY", "GOWORK", "GOTOOLCHAIN", "GOOS", "GOARCH", "GOMODCACHE",
+	"CGO_ENABLED", "CGO_CFLAGS", "CGO_CXXFLAGS", "CGO_LDFLAGS", "CC", "CXX",
+}
+
+func trace(cmd *exec.Cmd) *debug.Span {
+	span := debug.Start(debug.Exec, strings.Join(cmd.Args, " "))
+	if span == nil {
+		return nil
+	}
+	dir := cmd.Dir
+	if dir == "" {
+		dir, _ = os.Getwd()
 	}
-	return exec.Command(name, args...)
+	span.Set("dir", dir)
+	env := cmd.Env
+	if env == nil {
+		env = os.Environ()
+	}
+	var vars []string
+	for _, keyval := range env {
+		key, _, _ := strings.Cut(keyval, "=")
+		for _, traced := range tracedEnv {
+			if key == traced {
+				vars = append(vars, keyval)
+			}
+		}
+	}
+	return span.Set("env", strings.Join(vars, " "))
+}
+
+// Run runs a command and traces it.
+func Run(cmd *exec.Cmd) error {
+	span := trace(cmd)
+	err := cmd.Run()
+	span.End(err)
+	return err
+}
+
+// Output runs a command, traces it, and returns its standard output.
+func Output(cmd *exec.Cmd) ([]byte, error) {
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	err := Run(cmd)
+	return out.Bytes(), err
 }
@@ -16,27 +16,174 @@
 package gotc
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
+	"maps"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 
+	"golang.org/x/mod/modfile"
 	"golang.org/x/mod/module"
 )
 
+// Offline prevents the Go toolchain from accessing the network.
+// Modules are then only read from the module cache.
+var Offline bool
+
+// ReadOnly prevents the Go toolchain from updating go.mod and go.sum files.
+var ReadOnly bool
+
+// GoToolchain is the value of GOTOOLCHAIN passed to the go command
+// when GOTOOLCHAIN is not set in the environment.
+var GoToolchain string
+
+// setEnv sets the value of a variable in a list of environment variables.
+func setEnv(env []string, key, value string) []string {
+	prefix := key + "="
+	for i, keyval := range env {
+		if strings.HasPrefix(keyval, prefix) {
+			env[i] = prefix + value
+			return env
+		}
+	}
+	return append(env, prefix+value)
+}
+
+// userEnviron returns the environment of the user with the Go toolchain
+// selected by ccgx.
+func userEnviron() []string {
+	env := os.Environ()
+	if GoToolchain != "" && os.Getenv("GOTOOLCHAIN") == "" {
+		env = setEnv(env, "GOTOOLCHAIN", GoToolchain)
+	}
+	return env
+}
+
+// environ returns the environment in which the Go toolchain is run.
+func environ() []string {
+	env := userEnviron()
+	if !Offline && !ReadOnly {
+		return env
+	}
+	if Offline {
+		env = setEnv(env, "GOPROXY", "off")
+	}
+	if root := moduleRoot("."); root != "" && VendorDir(root) != "" {
+		// Vendored modules do not require any network access
+		// and go.mod and go.sum are not updated.
+		return env
+	}
+	if ReadOnly {
+		goflags := strings.TrimSpace(os.Getenv("GOFLAGS") + " -mod=readonly")
+		return setEnv(env, "GOFLAGS", goflags)
+	}
+	if WorkFile(".") != "" {
+		// -mod=mod is not supported in workspace mode.
+		return env
+	}
+	goflags := strings.TrimSpace(os.Getenv("GOFLAGS") + " -mod=mod")
+	return setEnv(env, "GOFLAGS", goflags)
+}
+
+// moduleRoot returns the root folder of the module in which dir is.
+// Returns an empty string if dir is not in a module.
+func moduleRoot(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		if fi, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil && !fi.IsDir() {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// WorkFile returns the path of the go.work file used by the Go toolchain
+// when run from dir. Returns an empty string if the Go toolchain is not
+// in workspace mode.
+func WorkFile(dir string) string {
+	switch gowork := os.Getenv("GOWORK"); gowork {
+	case "off":
+		return ""
+	case "":
+	default:
+		return gowork
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		path := filepath.Join(dir, "go.work")
+		if fi, err := os.Stat(path); err == nil && !fi.IsDir() {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// command returns a command running the Go toolchain.
+func command(args ...string) *Command {
+	return &Command{Args: args, Env: environ()}
+}
+
+// NoCheck is the annotation of the commands which do not require
+// a working Go installation, e.g. to diagnose a broken one.
+const NoCheck = "ccgx.gotc.nocheck"
+
 // Check that Go is installed.
 func Check() error {
-	cmd := exec.Command("go", "version")
+	cmd := command("version")
 	if _, err := cmd.Output(); err != nil {
 		return fmt.Errorf("invalid Go installation: %v", err)
 	}
 	return nil
 }
 
+// Env returns the values of Go environment variables
+// as seen by the go commands run by ccgx.
+func Env(keys ...string) (map[string]string, error) {
+	return goEnv(environ(), keys)
+}
+
+// UserEnv returns the values of Go environment variables as set by the user,
+// that is without the GOPROXY and GOFLAGS values set by ccgx when running
+// offline or in read-only mode.
+func UserEnv(keys ...string) (map[string]string, error) {
+	return goEnv(userEnviron(), keys)
+}
+
+func goEnv(environ []string, keys []string) (map[string]string, error) {
+	cmd := &Command{Args: append([]string{"env", "-json"}, keys...), Env: environ}
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read Go environment: %v", err)
+	}
+	env := make(map[string]string)
+	if err := json.Unmarshal(out, &env); err != nil {
+		return nil, fmt.Errorf("cannot parse Go environment: %v", err)
+	}
+	return env, nil
+}
+
 // ModInit runs the go mod init command.
 func ModInit(modName string) error {
-	cmd := exec.Command("go", "mod", "init", modName)
+	cmd := command("mod", "init", modName)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
@@ -44,7 +191,44 @@ func ModInit(modName string) error {
 
 // ModTidy runs the go mod tidy command.
 func ModTidy() error {
-	cmd := exec.Command("go", "mod", "tidy")
+	cmd := command("mod", "tidy")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// Get runs the go get command in the module at root.
+func Get(root string, args ...string) error {
+	cmd := command(append([]string{"get"}, args...)...)
+	cmd.Dir = root
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// RunTool runs a tool declared in the go.mod file of the module at root.
+func RunTool(root, name string, args ...string) error {
+	cmd := command(append([]string{"tool", name}, args...)...)
+	cmd.Dir = root
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// RunPackage runs a main package at a version, e.g. example.com/cmd@v1.2,
+// in the current folder with the standard input and outputs of ccgx.
+func RunPackage(pkg string, args ...string) error {
+	cmd := command(append([]string{"run", pkg}, args...)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// WorkSync runs the go work sync command.
+func WorkSync() error {
+	cmd := command("work", "sync")
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
@@ -59,74 +243,373 @@ type Cache struct {
 
 // NewCache reads where Go caches modules.
 func NewCache() (*Cache, error) {
-	cmd := exec.Command("go", "env")
-	out, err := cmd.CombinedOutput()
+	env, err := Env(goModCache)
 	if err != nil {
 		return nil, err
 	}
-	for line := range strings.Lines(string(out)) {
-		spLine := strings.Split(line, "=")
-		if len(spLine) != 2 {
+	cachePath := env[goModCache]
+	if cachePath == "" {
+		return nil, fmt.Errorf("Go variable environment %s not found", goModCache)
+	}
+	return &Cache{path: cachePath}, nil
+}
+
+// Path returns the path of the module cache.
+func (cache *Cache) Path() string {
+	return cache.path
+}
+
+// Has returns true if the module cache has downloaded the source of a
+// given module, that is its zip file or its extracted folder. Having only
+// the go.mod file of a module is not enough to build it.
+func (cache *Cache) Has(mod module.Version) bool {
+	escPath, err := module.EscapePath(mod.Path)
+	if err != nil {
+		return false
+	}
+	escVersion, err := module.EscapeVersion(mod.Version)
+	if err != nil {
+		return false
+	}
+	zipFile := filepath.Join(cache.path, "cache", "download", escPath, "@v", escVersion+".zip")
+	if _, err := os.Stat(zipFile); err == nil {
+		return true
+	}
+	info, err := os.Stat(filepath.Join(cache.path, escPath+"@"+escVersion))
+	return err == nil && info.IsDir()
+}
+
+// CheckCache returns an error listing the modules missing from the module cache.
+func CheckCache(cache *Cache, mods []module.Version) error {
+	var missing []string
+	for _, mod := range mods {
+		if cache.Has(mod) {
 			continue
 		}
-		if spLine[0] != goModCache {
-			continue
+		missing = append(missing, mod.String())
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return fmt.Errorf("modules missing from the module cache %s:\n\t%s\nRun the following command with network access to download them:\n\tgo mod download %s",
+		cache.path,
+		strings.Join(missing, "\n\t"),
+		strings.Join(missing, " "))
+}
+
+// Module is a module as described by go list -m -json.
+type Module struct {
+	Path     string
+	Version  string
+	Replace  *Module
+	Dir      string
+	Main     bool
+	Indirect bool
+	Error    *ModuleError
+}
+
+// ModuleError is an error loading a module.
+type ModuleError struct {
+	Err string
+}
+
+// ListModules runs go list -m -json in the module at root.
+// args are additional flags and module paths passed to go list.
+// Errors specific to a module are reported in the Error field of the module.
+func ListModules(root string, args ...string) ([]*Module, error) {
+	cmd := command(append([]string{"list", "-m", "-e", "-json"}, args...)...)
+	cmd.Dir = root
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("cannot list modules: %v", err)
+	}
+	var mods []*Module
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for {
+		mod := &Module{}
+		err := dec.Decode(mod)
+		if err == io.EOF {
+			break
 		}
-		cachePath := strings.TrimSpace(spLine[1])
-		cachePath = strings.TrimPrefix(cachePath, "'")
-		cachePath = strings.TrimSuffix(cachePath, "'")
-		return &Cache{path: cachePath}, nil
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse go list output: %v", err)
+		}
+		mods = append(mods, mod)
 	}
-	return nil, fmt.Errorf("Go variable environment %s not found", goModCache)
+	return mods, nil
 }
 
-// ModuleOSPath returns the OS path of a given module path and its version.
-func (cache *Cache) OSPath(mod *module.Version) (string, error) {
-	dir, file := filepath.Split(mod.Path)
-	modCache := filepath.Join(cache.path, dir, fmt.Sprintf("%s@%s", file, mod.Version))
-	dirStat, err := os.Stat(modCache)
+// VendorPath returns the path of the vendor folder of the module at root.
+// In a Go workspace, the vendor folder is at the root of the workspace.
+func VendorPath(root string) string {
+	if work := WorkFile(root); work != "" {
+		root = filepath.Dir(work)
+	}
+	return filepath.Join(root, "vendor")
+}
+
+// VendorDir returns the vendor folder of the module at root
+// if the Go toolchain builds the module in vendor mode.
+// Returns an empty string otherwise.
+func VendorDir(root string) string {
+	goflags := os.Getenv("GOFLAGS")
+	if strings.Contains(goflags, "-mod=mod") || strings.Contains(goflags, "-mod=readonly") {
+		return ""
+	}
+	vendor := VendorPath(root)
+	if _, err := os.Stat(filepath.Join(vendor, "modules.txt")); err != nil {
+		return ""
+	}
+	return vendor
+}
+
+// ModVendor runs go mod vendor or, in a Go workspace, go work vendor.
+func ModVendor(root string) error {
+	cmdName := "mod"
+	if WorkFile(root) != "" {
+		cmdName = "work"
+	}
+	cmd := command(cmdName, "vendor")
+	cmd.Dir = root
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// ModuleDirs returns the directories of dependencies of the module at root
+// as resolved by the Go toolchain. This takes into account case-escaping of
+// module paths, replace directives, and vendored modules.
+// The returned map is indexed by module path.
+func ModuleDirs(root string, deps []*module.Version) (map[string]string, error) {
+	return moduleDirs(root, deps, VendorDir(root))
+}
+
+// SourceModuleDirs returns the directories of dependencies of the module at root
+// like ModuleDirs but ignores the vendor folder.
+func SourceModuleDirs(root string, deps []*module.Version) (map[string]string, error) {
+	if VendorDir(root) == "" {
+		return moduleDirs(root, deps, "")
+	}
+	return moduleDirs(root, deps, "", "-mod=readonly")
+}
+
+func moduleDirs(root string, deps []*module.Version, vendor string, flags ...string) (map[string]string, error) {
+	args := flags
+	for _, dep := range deps {
+		args = append(args, dep.Path)
+	}
+	mods, err := ListModules(root, args...)
+	if err != nil {
+		return nil, err
+	}
+	dirs := make(map[string]string)
+	var errs []string
+	missing := make(map[module.Version]string)
+	for _, mod := range mods {
+		if vendor != "" {
+			vendored := filepath.Join(vendor, filepath.FromSlash(mod.Path))
+			if dirStat, err := os.Stat(vendored); err == nil && dirStat.IsDir() {
+				dirs[mod.Path] = vendored
+				continue
+			}
+		}
+		if mod.Dir != "" {
+			dirs[mod.Path] = mod.Dir
+			continue
+		}
+		// When running offline, errors are caused by modules missing from the cache.
+		if mod.Error != nil && !Offline {
+			errs = append(errs, fmt.Sprintf("%s@%s: %s", mod.Path, mod.Version, mod.Error.Err))
+			continue
+		}
+		// The module needs to be downloaded in the module cache.
+		target := module.Version{Path: mod.Path, Version: mod.Version}
+		if mod.Replace != nil {
+			if mod.Replace.Version == "" {
+				errs = append(errs, fmt.Sprintf("%s@%s: replacement folder %s not found", mod.Path, mod.Version, mod.Replace.Path))
+				continue
+			}
+			target = module.Version{Path: mod.Replace.Path, Version: mod.Replace.Version}
+		}
+		missing[target] = mod.Path
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("cannot find GX module directories:\n\t%s\nPlease run ccgx mod tidy.", strings.Join(errs, "\n\t"))
+	}
+	if len(missing) == 0 {
+		return dirs, nil
+	}
+	downloaded, err := Download(root, slices.Collect(maps.Keys(missing)))
 	if err != nil {
-		return "", fmt.Errorf("cannot find GX module path at %s: %v\nPlease run ccgx tidy.\n", modCache, err)
+		return nil, err
 	}
-	if !dirStat.IsDir() {
-		return "", fmt.Errorf("%s not a directory: %v\nPlease run ccgx tidy.\n", modCache, err)
+	for target, dir := range downloaded {
+		dirs[missing[target]] = dir
 	}
-	return modCache, nil
+	return dirs, nil
 }
 
-func runCGOCommand(root string, cmd *exec.Cmd) error {
-	const cflagsKey = "CGO_CFLAGS"
-	var cflagsValue string
-	envs := os.Environ()
-	for _, env := range envs {
-		keyvals := strings.Split(env, "=")
-		if len(keyvals) != 2 {
-			cmd.Env = append(cmd.Env, env)
+// Download downloads modules in the module cache and checks their checksums
+// against the go.sum file of the module at root.
+// Returns the folders of the modules in the module cache.
+func Download(root string, mods []module.Version) (map[module.Version]string, error) {
+	args := make([]string, len(mods))
+	for i, mod := range mods {
+		args[i] = mod.String()
+	}
+	sort.Strings(args)
+	if Offline {
+		return nil, fmt.Errorf("running offline but modules are missing from the module cache:\n\t%s\nRun the following command with network access to download them:\n\tgo mod download %s",
+			strings.Join(args, "\n\t"),
+			strings.Join(args, " "))
+	}
+	sums, err := readGoSum(root)
+	if err != nil {
+		return nil, err
+	}
+	cmd := command(append([]string{"mod", "download", "-json"}, args...)...)
+	cmd.Dir = root
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil && len(out) == 0 {
+		return nil, fmt.Errorf("cannot download modules %s: %v", strings.Join(args, " "), err)
+	}
+	dirs := make(map[module.Version]string)
+	var errs []string
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for {
+		var dl struct {
+			Path    string
+			Version string
+			Error   string
+			Dir     string
+			Sum     string
+		}
+		err := dec.Decode(&dl)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse go mod download output: %v", err)
+		}
+		mod := module.Version{Path: dl.Path, Version: dl.Version}
+		if dl.Error != "" {
+			errs = append(errs, fmt.Sprintf("%s: %s", mod, dl.Error))
+			continue
+		}
+		want, ok := sums[mod]
+		if !ok {
+			errs = append(errs, fmt.Sprintf("%s: missing go.sum entry", mod))
+			continue
+		}
+		if dl.Sum != want {
+			errs = append(errs, fmt.Sprintf("%s: checksum mismatch\n\t\tdownloaded: %s\n\t\tgo.sum:     %s", mod, dl.Sum, want))
 			continue
 		}
-		key, val := keyvals[0], keyvals[1]
-		if key != cflagsKey {
-			cmd.Env = append(cmd.Env, env)
+		dirs[mod] = dl.Dir
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("cannot download modules:\n\t%s", strings.Join(errs, "\n\t"))
+	}
+	return dirs, nil
+}
+
+// readGoSum reads the checksums of module contents from the go.sum file
+// of the module at root and, in a Go workspace, from the go.sum files of
+// all the modules of the workspace and from the go.work.sum file.
+func readGoSum(root string) (map[module.Version]string, error) {
+	paths := []string{filepath.Join(root, "go.sum")}
+	if work := WorkFile(root); work != "" {
+		dirs, err := workModuleDirs(work)
+		if err != nil {
+			return nil, err
+		}
+		for _, dir := range dirs {
+			paths = append(paths, filepath.Join(dir, "go.sum"))
+		}
+		paths = append(paths, work+".sum")
+	}
+	sums := make(map[module.Version]string)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
 			continue
 		}
-		cflagsValue = val
+		if err != nil {
+			return nil, err
+		}
+		for line := range strings.Lines(string(data)) {
+			fields := strings.Fields(line)
+			if len(fields) != 3 || strings.HasSuffix(fields[1], "/go.mod") {
+				continue
+			}
+			sums[module.Version{Path: fields[0], Version: fields[1]}] = fields[2]
+		}
+	}
+	return sums, nil
+}
+
+// workModuleDirs returns the folders of the modules used by a go.work file.
+func workModuleDirs(work string) ([]string, error) {
+	data, err := os.ReadFile(work)
+	if err != nil {
+		return nil, err
+	}
+	file, err := modfile.ParseWork(work, data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse %s: %v", work, err)
+	}
+	dirs := make([]string, len(file.Use))
+	for i, use := range file.Use {
+		dirs[i] = use.Path
+		if !filepath.IsAbs(dirs[i]) {
+			dirs[i] = filepath.Join(filepath.Dir(work), dirs[i])
+		}
 	}
-	cmd.Env = append(cmd.Env, cflagsKey+"="+cflagsValue+" -I "+root)
+	return dirs, nil
+}
+
+func runCGOCommand(root string, cmd *Command) error {
+	const cflagsKey = "CGO_CFLAGS"
+	cmd.Env = setEnv(cmd.Env, cflagsKey, os.Getenv(cflagsKey)+" -I "+root)
+	cmd.Dir = root
+	// Errors are captured to be reported as diagnostics.
 	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
 	return cmd.Run()
 }
 
+// buildOutput runs build with a path in a temporary folder next to target,
+// then moves the file at that path to target. target is left untouched if
+// the build fails or is interrupted.
+func buildOutput(target string, build func(tmpTarget, tmpDir string) error) error {
+	tmpDir, err := os.MkdirTemp(filepath.Dir(target), ".ccgx-build-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+	tmpTarget := filepath.Join(tmpDir, filepath.Base(target))
+	if err := build(tmpTarget, tmpDir); err != nil {
+		return err
+	}
+	return os.Rename(tmpTarget, target)
+}
+
 func BuildCGoHeader(root, src, target string) error {
-	return runCGOCommand(root, exec.Command("go", "tool", "cgo",
-		"-exportheader", target,
-		src))
+	return buildOutput(target, func(tmpTarget, tmpDir string) error {
+		return runCGOCommand(root, command("tool", "cgo",
+			"-objdir", filepath.Join(tmpDir, "_obj"),
+			"-exportheader", tmpTarget,
+			src))
+	})
 }
 
 func BuildArchive(root, src, target string) error {
-	return runCGOCommand(root, exec.Command("go", "build",
-		"-buildmode=c-archive",
-		"-o",
-		target,
-		src))
+	return buildOutput(target, func(tmpTarget, tmpDir string) error {
+		return runCGOCommand(root, command("build",
+			"-buildmode=c-archive",
+			"-o",
+			tmpTarget,
+			src))
+	})
 }
@@ -0,0 +1,151 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gotc
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"golang.org/x/mod/module"
+)
+
+// fakeGo is a toolchain writing a canned output instead of running go.
+type fakeGo string
+
+func (f fakeGo) Run(ctx context.Context, cmd *Command) error {
+	_, err := io.WriteString(cmd.Stdout, string(f))
+	return err
+}
+
+const depSum = "h1:6Yz8Kf0w2XbO0WBtOiT2bJ2mS9fQk5N1c3xUuVn8qEw="
+
+func TestDownload(t *testing.T) {
+	fixture, err := filepath.Abs(filepath.Join("..", "..", "tests", "toolchain"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dep := module.Version{Path: "example.com/dep", Version: "v1.0.0"}
+	tests := []struct {
+		name string
+		// workspace runs the download in a module of a Go workspace
+		// using the fixture module.
+		workspace bool
+		stdout    string
+		want      map[module.Version]string
+		wantErr   string
+	}{
+		{
+			name:   "ok",
+			stdout: `{"Path": "example.com/dep", "Version": "v1.0.0", "Dir": "/cache/dep", "Sum": "` + depSum + `"}`,
+			want:   map[module.Version]string{dep: "/cache/dep"},
+		},
+		{
+			name:      "workspace",
+			workspace: true,
+			stdout:    `{"Path": "example.com/dep", "Version": "v1.0.0", "Dir": "/cache/dep", "Sum": "` + depSum + `"}`,
+			want:      map[module.Version]string{dep: "/cache/dep"},
+		},
+		{
+			name:    "mismatch",
+			stdout:  `{"Path": "example.com/dep", "Version": "v1.0.0", "Dir": "/cache/dep", "Sum": "h1:other="}`,
+			wantErr: "checksum mismatch",
+		},
+		{
+			name:    "error",
+			stdout:  `{"Path": "example.com/dep", "Version": "v1.0.0", "Error": "not found"}`,
+			wantErr: "not found",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := fixture
+			t.Setenv("GOWORK", "off")
+			if test.workspace {
+				root = t.TempDir()
+				if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/main\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+				work := "go 1.24.4\n\nuse (\n\t.\n\t" + fixture + "\n)\n"
+				if err := os.WriteFile(filepath.Join(root, "go.work"), []byte(work), 0644); err != nil {
+					t.Fatal(err)
+				}
+				t.Setenv("GOWORK", "")
+			}
+			rec := &Recorder{Toolchain: fakeGo(test.stdout)}
+			prev := Go
+			Go = rec
+			defer func() { Go = prev }()
+			got, err := Download(root, []module.Version{dep})
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("Download() error = %v, want %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(test.want) || got[dep] != test.want[dep] {
+				t.Errorf("Download() = %v, want %v", got, test.want)
+			}
+			cmds := rec.Commands()
+			wantArgs := []string{"mod", "download", "-json", "example.com/dep@v1.0.0"}
+			if len(cmds) != 1 || !slices.Equal(cmds[0].Args, wantArgs) || cmds[0].Dir != root {
+				t.Errorf("go commands = %v, want go %v in %s", cmds, wantArgs, root)
+			}
+		})
+	}
+}
+
+func TestUserEnv(t *testing.T) {
+	t.Setenv("GOFLAGS", "-trimpath")
+	t.Setenv("GOPROXY", "https://proxy.example.com")
+	t.Setenv("GOWORK", "off")
+	tests := []struct {
+		name     string
+		offline  bool
+		readOnly bool
+	}{
+		{name: "online"},
+		{name: "offline", offline: true},
+		{name: "read-only", readOnly: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			Offline, ReadOnly = test.offline, test.readOnly
+			rec := &Recorder{Toolchain: fakeGo("{}")}
+			prev := Go
+			Go = rec
+			defer func() {
+				Go = prev
+				Offline, ReadOnly = false, false
+			}()
+			if _, err := UserEnv("GOFLAGS", "GOPROXY"); err != nil {
+				t.Fatal(err)
+			}
+			env := rec.Commands()[0].Env
+			for _, want := range []string{"GOFLAGS=-trimpath", "GOPROXY=https://proxy.example.com"} {
+				if !slices.Contains(env, want) {
+					t.Errorf("go env runs without %s", want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gotc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gx-org/ccgx/internal/diag"
+	"github.com/gx-org/ccgx/internal/exec"
+)
+
+// Command is an invocation of the go command.
+type Command struct {
+	// Args are the arguments passed to the go command.
+	Args []string
+	// Dir is the folder in which the command runs.
+	// The command runs in the current folder if empty.
+	Dir string
+	// Env is the environment of the command.
+	Env []string
+
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// Context is the context in which go commands run.
+// Running commands are killed when the context is done.
+var Context = context.Background()
+
+// Timeout is the maximum duration of a go command. No timeout if zero.
+var Timeout time.Duration
+
+// Run runs the command with the current toolchain.
+// The standard error of the command is captured, attached to the
+// returned error, and parsed for diagnostics. It is also written
+// to the standard error of the command if set.
+func (cmd *Command) Run() error {
+	ctx := Context
+	if Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, Timeout)
+		defer cancel()
+	}
+	var stderr bytes.Buffer
+	run := *cmd
+	run.Stderr = &stderr
+	if cmd.Stderr != nil {
+		run.Stderr = io.MultiWriter(cmd.Stderr, &stderr)
+	}
+	err := Go.Run(ctx, &run)
+	if err == nil {
+		return nil
+	}
+	switch ctxErr := ctx.Err(); {
+	case errors.Is(ctxErr, context.DeadlineExceeded):
+		err = fmt.Errorf("timed out after %v", Timeout)
+	case ctxErr != nil:
+		err = fmt.Errorf("interrupted")
+	}
+	output := strings.TrimSpace(stderr.String())
+	return &diag.Error{
+		Msg:         fmt.Sprintf("go %s: %v", strings.Join(cmd.Args, " "), err),
+		Diagnostics: diag.Parse(cmd.Dir, output),
+		Output:      output,
+	}
+}
+
+// Output runs the command with the current toolchain
+// and returns its standard output.
+func (cmd *Command) Output() ([]byte, error) {
+	if cmd.Stdout != nil {
+		return nil, fmt.Errorf("go %v: Stdout already set", cmd.Args)
+	}
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	err := cmd.Run()
+	return out.Bytes(), err
+}
+
+// Toolchain runs go commands.
+// Toolchains can be swapped, for example to run ccgx against a fake.
+type Toolchain interface {
+	// Run runs a go command. The command is killed when ctx is done.
+	Run(ctx context.Context, cmd *Command) error
+}
+
+// Binary is a toolchain running a go binary.
+// The binary is looked up in the PATH if the name has no path separator.
+type Binary string
+
+// Run runs a go command with the binary.
+func (bin Binary) Run(ctx context.Context, cmd *Command) error {
+	c := exec.CommandContext(ctx, string(bin), cmd.Args...)
+	c.Dir = cmd.Dir
+	c.Env = cmd.Env
+	c.Stdin = cmd.Stdin
+	c.Stdout = cmd.Stdout
+	c.Stderr = cmd.Stderr
+	return exec.Run(c)
+}
+
+// Go is the toolchain used by ccgx.
+var Go Toolchain = Binary("go")
+
+// Recorder records the commands run by a toolchain.
+type Recorder struct {
+	// Toolchain runs the commands. Commands are only recorded if nil.
+	Toolchain Toolchain
+
+	mut      sync.Mutex
+	commands []*Command
+}
+
+// Run records a command, then runs it with the underlying toolchain.
+func (rec *Recorder) Run(ctx context.Context, cmd *Command) error {
+	rec.mut.Lock()
+	rec.commands = append(rec.commands, cmd)
+	rec.mut.Unlock()
+	if rec.Toolchain == nil {
+		return nil
+	}
+	return rec.Toolchain.Run(ctx, cmd)
+}
+
+// Commands returns the commands recorded so far.
+func (rec *Recorder) Commands() []*Command {
+	rec.mut.Lock()
+	defer rec.mut.Unlock()
+	return append([]*Command(nil), rec.commands...)
+}
@@ -0,0 +1,184 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gxtc
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gx-org/ccgx/internal/cmd/debug"
+	"github.com/gx-org/ccgx/internal/gotc"
+	"github.com/gx-org/gx/build/builder"
+	"github.com/gx-org/gx/build/importers/localfs"
+	gomodule "golang.org/x/mod/module"
+)
+
+// Dep is a dependency of a workspace.
+type Dep struct {
+	// Path of the module.
+	Path string
+	// Version of the module.
+	Version string
+	// Dir is the folder of the module.
+	Dir string
+	// GXFiles is the number of GX source files in the module.
+	GXFiles int
+	// Headers is the number of C/C++ header files in the module.
+	Headers int
+}
+
+// Linked returns true if the dependency needs to be linked in gxdeps,
+// that is if it provides GX source files or C/C++ headers.
+func (dep *Dep) Linked() bool {
+	return dep.GXFiles > 0 || dep.Headers > 0
+}
+
+// Reason returns why a dependency is linked or not.
+func (dep *Dep) Reason() string {
+	if !dep.Linked() {
+		return "no GX source file or C/C++ header"
+	}
+	var reasons []string
+	if dep.GXFiles > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d GX source files", dep.GXFiles))
+	}
+	if dep.Headers > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d C/C++ headers", dep.Headers))
+	}
+	return strings.Join(reasons, ", ")
+}
+
+var headerExts = map[string]bool{
+	".h":   true,
+	".hh":  true,
+	".hpp": true,
+	".hxx": true,
+}
+
+// skipModuleEntry returns true for the entries of a module folder ignored by ccgx.
+func skipModuleEntry(entry fs.DirEntry) bool {
+	name := entry.Name()
+	return name == "testdata" || name == gxdepsFolderName || strings.HasPrefix(name, ".")
+}
+
+// classify counts the GX source files and C/C++ headers of a dependency.
+func (dep *Dep) classify() error {
+	return filepath.WalkDir(dep.Dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != dep.Dir && skipModuleEntry(entry) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ext := filepath.Ext(entry.Name())
+		switch {
+		case ext == ".gx":
+			dep.GXFiles++
+		case headerExts[ext]:
+			dep.Headers++
+		}
+		return nil
+	})
+}
+
+// dependencies returns the modules which are candidates to be linked in gxdeps.
+func (ws *Workspace) dependencies() []*gomodule.Version {
+	var deps []*gomodule.Version
+	seen := map[string]bool{ws.Main.Name(): true}
+	for _, mod := range ws.Modules {
+		// Bindings of the modules being processed are written in gxdeps.
+		seen[mod.Name()] = true
+	}
+	for _, mod := range ws.members {
+		modDeps := append(mod.Deps(), &gomodule.Version{Path: mod.Name()})
+		for _, dep := range modDeps {
+			if seen[dep.Path] {
+				continue
+			}
+			seen[dep.Path] = true
+			deps = append(deps, dep)
+		}
+	}
+	// Requirements added by ccgx mod get are indirect until imported.
+	for _, req := range ws.Require {
+		if !seen[req.Path] {
+			seen[req.Path] = true
+			deps = append(deps, &req)
+		}
+	}
+	return deps
+}
+
+// Deps returns the dependencies of a workspace and whether they need
+// to be linked in gxdeps.
+func Deps(ws *Workspace) ([]*Dep, error) {
+	return deps(ws, gotc.ModuleDirs)
+}
+
+func deps(ws *Workspace, moduleDirs func(string, []*gomodule.Version) (map[string]string, error)) (_ []*Dep, err error) {
+	span := debug.Start(debug.Step, "deps")
+	defer func() { span.End(err) }()
+	return loadDeps(ws, ws.dependencies(), moduleDirs)
+}
+
+// ModuleDeps returns the given modules, required by the main module of a
+// workspace, as dependencies. Unlike Deps, indirect requirements are supported.
+func ModuleDeps(ws *Workspace, mods []*gomodule.Version) ([]*Dep, error) {
+	return loadDeps(ws, mods, gotc.ModuleDirs)
+}
+
+func loadDeps(ws *Workspace, mods []*gomodule.Version, moduleDirs func(string, []*gomodule.Version) (map[string]string, error)) ([]*Dep, error) {
+	if len(mods) == 0 {
+		return nil, nil
+	}
+	dirs, err := moduleDirs(ws.Main.Root(), mods)
+	if err != nil {
+		return nil, err
+	}
+	deps := make([]*Dep, len(mods))
+	for i, mod := range mods {
+		deps[i] = &Dep{
+			Path:    mod.Path,
+			Version: mod.Version,
+			Dir:     dirs[mod.Path],
+		}
+		if err := deps[i].classify(); err != nil {
+			return nil, fmt.Errorf("cannot read module %s: %v", mod.Path, err)
+		}
+	}
+	return deps, nil
+}
+
+// depImporter imports GX packages from the folder of a dependency.
+type depImporter struct {
+	dep *Dep
+}
+
+// Support returns true if the package belongs to the dependency.
+func (imp depImporter) Support(path string) bool {
+	return path == imp.dep.Path || strings.HasPrefix(path, imp.dep.Path+"/")
+}
+
+// Import a package from the folder of the dependency.
+func (imp depImporter) Import(bld *builder.Builder, path string) (builder.Package, error) {
+	pkgPath := strings.TrimPrefix(strings.TrimPrefix(path, imp.dep.Path), "/")
+	return localfs.ImportAt(bld, os.DirFS(imp.dep.Dir).(fs.ReadDirFS), path, pkgPath)
+}
@@ -0,0 +1,269 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gxtc
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gx-org/gx/stdlib"
+	gomodule "golang.org/x/mod/module"
+)
+
+// PackageKind is the origin of a GX package in an import graph.
+type PackageKind string
+
+const (
+	// LocalPackage is a package of a module of the workspace.
+	LocalPackage PackageKind = "local"
+	// DependencyPackage is a package of a dependency module.
+	DependencyPackage PackageKind = "dependency"
+	// StdlibPackage is a package of the GX standard library.
+	StdlibPackage PackageKind = "stdlib"
+	// UnresolvedPackage is a package which cannot be found.
+	UnresolvedPackage PackageKind = "unresolved"
+)
+
+// ImportGraph is the graph of the GX imports of the packages
+// of a workspace imported by the C archive.
+type ImportGraph struct {
+	// Roots are the packages imported by the C archive.
+	Roots []string
+	// Imports maps each package of the graph to its sorted imports.
+	Imports map[string][]string
+	// Kinds maps each package of the graph to its origin.
+	Kinds map[string]PackageKind
+}
+
+// NewImportGraph returns the import graph of the selected packages of a workspace.
+func NewImportGraph(ws *Workspace) (*ImportGraph, error) {
+	r := &importResolver{ws: ws, std: stdlib.Importer(nil)}
+	g := &ImportGraph{
+		Imports: make(map[string][]string),
+		Kinds:   make(map[string]PackageKind),
+	}
+	for _, mod := range ws.boundModules() {
+		pkgs, err := ws.packages(mod)
+		if err != nil {
+			return nil, err
+		}
+		g.Roots = append(g.Roots, pkgs...)
+	}
+	sort.Strings(g.Roots)
+	queue := append([]string{}, g.Roots...)
+	for len(queue) > 0 {
+		pkg := queue[0]
+		queue = queue[1:]
+		if _, done := g.Kinds[pkg]; done {
+			continue
+		}
+		kind, dir := LocalPackage, ""
+		member := ws.moduleOf(pkg)
+		switch {
+		case r.std.Support(pkg):
+			kind = StdlibPackage
+		case member != nil:
+			dir = filepath.Join(member.Root(), filepath.FromSlash(strings.TrimPrefix(pkg, member.Name())))
+		default:
+			kind = UnresolvedPackage
+			dep, err := r.provider(pkg)
+			if err != nil {
+				return nil, err
+			}
+			if dep != nil {
+				kind = DependencyPackage
+				dir = filepath.Join(dep.Dir, filepath.FromSlash(strings.TrimPrefix(pkg, dep.Path)))
+			}
+		}
+		g.Kinds[pkg] = kind
+		if dir == "" {
+			continue
+		}
+		imps, err := dirImports(dir)
+		if err != nil {
+			return nil, err
+		}
+		g.Imports[pkg] = imps
+		queue = append(queue, imps...)
+	}
+	return g, nil
+}
+
+// provider returns the dependency with a folder providing a package.
+// Indirect requirements of the modules of the workspace, not in the
+// dependencies of the workspace, are loaded on demand.
+// Returns nil if no dependency provides the package.
+func (r *importResolver) provider(pkg string) (*Dep, error) {
+	if err := r.loadDeps(); err != nil {
+		return nil, err
+	}
+	if dep := providerOf(slices.Collect(maps.Values(r.deps)), pkg); dep != nil {
+		return dep, nil
+	}
+	var req gomodule.Version
+	for _, mod := range r.ws.members {
+		if found := requirementOf(mod, pkg); len(found.Path) > len(req.Path) {
+			req = found
+		}
+	}
+	if req.Path == "" {
+		return nil, nil
+	}
+	dep, err := r.dep(req)
+	if err != nil || dep.Dir == "" {
+		return nil, err
+	}
+	return dep, nil
+}
+
+// providerOf returns the dependency with a folder providing a package.
+// Returns nil if no dependency provides the package.
+func providerOf(deps []*Dep, pkg string) *Dep {
+	var found *Dep
+	for _, dep := range deps {
+		if dep.Dir == "" || (pkg != dep.Path && !strings.HasPrefix(pkg, dep.Path+"/")) {
+			continue
+		}
+		// Nested modules: select the longest module path.
+		if found == nil || len(dep.Path) > len(found.Path) {
+			found = dep
+		}
+	}
+	return found
+}
+
+// dirImports returns the GX imports of the GX source files in a folder.
+func dirImports(dir string) ([]string, error) {
+	names, err := gxSourceFiles(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var imps []string
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.ImportsOnly)
+		if err != nil {
+			return nil, err
+		}
+		for _, imp := range file.Imports {
+			impPath, err := strconv.Unquote(imp.Path.Value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: import path %q is invalid: %v", path, imp.Path.Value, err)
+			}
+			imps = append(imps, impPath)
+		}
+	}
+	imps = unique(imps)
+	sort.Strings(imps)
+	return imps, nil
+}
+
+// packagesSorted returns all the packages of the graph, sorted.
+func (g *ImportGraph) packagesSorted() []string {
+	pkgs := make([]string, 0, len(g.Kinds))
+	for pkg := range g.Kinds {
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Strings(pkgs)
+	return pkgs
+}
+
+// WriteText writes the graph with one "package import" line per edge,
+// like go mod graph.
+func (g *ImportGraph) WriteText(w io.Writer) error {
+	for _, pkg := range g.packagesSorted() {
+		for _, imp := range g.Imports[pkg] {
+			if _, err := fmt.Fprintf(w, "%s %s\n", pkg, imp); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+var dotStyles = map[PackageKind]string{
+	LocalPackage:      "shape=box",
+	DependencyPackage: "shape=ellipse",
+	StdlibPackage:     "shape=ellipse, style=dashed",
+	UnresolvedPackage: "shape=ellipse, color=red",
+}
+
+// WriteDOT writes the graph in the Graphviz DOT language.
+func (g *ImportGraph) WriteDOT(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph gx {\n\trankdir=LR;\n")
+	roots := make(map[string]bool)
+	for _, root := range g.Roots {
+		roots[root] = true
+	}
+	for _, pkg := range g.packagesSorted() {
+		style := dotStyles[g.Kinds[pkg]]
+		if roots[pkg] {
+			style += ", peripheries=2"
+		}
+		fmt.Fprintf(&b, "\t%s [%s];\n", strconv.Quote(pkg), style)
+	}
+	for _, pkg := range g.packagesSorted() {
+		for _, imp := range g.Imports[pkg] {
+			fmt.Fprintf(&b, "\t%s -> %s;\n", strconv.Quote(pkg), strconv.Quote(imp))
+		}
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Why returns the shortest chain of imports from a package imported by the
+// C archive to a package matching a function. Returns nil if no package of
+// the graph matches.
+func (g *ImportGraph) Why(match func(string) bool) []string {
+	prev := make(map[string]string)
+	queue := []string{}
+	for _, root := range g.Roots {
+		prev[root] = ""
+		queue = append(queue, root)
+	}
+	for len(queue) > 0 {
+		pkg := queue[0]
+		queue = queue[1:]
+		if match(pkg) {
+			var chain []string
+			for p := pkg; p != ""; p = prev[p] {
+				chain = append([]string{p}, chain...)
+			}
+			return chain
+		}
+		for _, imp := range g.Imports[pkg] {
+			if _, seen := prev[imp]; seen {
+				continue
+			}
+			prev[imp] = pkg
+			queue = append(queue, imp)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gxtc
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	gxmodule "github.com/gx-org/gx/build/module"
+)
+
+func TestProviderOf(t *testing.T) {
+	deps := []*Dep{
+		{Path: "example.com/a", Dir: "/a"},
+		{Path: "example.com/a/nested", Dir: "/nested"},
+		{Path: "example.com/b", Dir: ""},
+		{Path: "example.com/c", Dir: "/c"},
+	}
+	tests := []struct {
+		pkg  string
+		want string
+	}{
+		{pkg: "example.com/a", want: "example.com/a"},
+		{pkg: "example.com/a/p", want: "example.com/a"},
+		{pkg: "example.com/a/nested/p", want: "example.com/a/nested"},
+		{pkg: "example.com/ab/p"},
+		{pkg: "example.com/b/p"},
+		{pkg: "example.com/d"},
+	}
+	for _, test := range tests {
+		got := ""
+		if dep := providerOf(deps, test.pkg); dep != nil {
+			got = dep.Path
+		}
+		if got != test.want {
+			t.Errorf("providerOf(%q) = %q, want %q", test.pkg, got, test.want)
+		}
+	}
+}
+
+func TestNewImportGraph(t *testing.T) {
+	tests := []struct {
+		name   string
+		goMod  string
+		wantGo [][]string
+	}{
+		{
+			name:   "direct",
+			goMod:  "module example.com/toolchain\n\ngo 1.24.4\n\nrequire example.com/dep v1.0.0\n",
+			wantGo: [][]string{{"list", "-m", "-e", "-json", "example.com/dep"}},
+		},
+		{
+			name:   "indirect",
+			goMod:  "module example.com/toolchain\n\ngo 1.24.4\n\nrequire example.com/dep v1.0.0 // indirect\n",
+			wantGo: [][]string{{"list", "-m", "-e", "-json", "example.com/dep"}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ws, rec := newTestWorkspace(t)
+			root := ws.Main.Root()
+			// Files of the copy are hard links to the files of tests/toolchain.
+			if err := os.Remove(filepath.Join(root, "go.mod")); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte(test.goMod), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.MkdirAll(filepath.Join(root, "c"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			src := "package c\n\nimport (\n\t\"example.com/dep/d\"\n\t\"example.com/missing/m\"\n)\n"
+			if err := os.WriteFile(filepath.Join(root, "c", "c.gx"), []byte(src), 0644); err != nil {
+				t.Fatal(err)
+			}
+			mod, err := gxmodule.New(root)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ws, err = NewWorkspace(mod, nil); err != nil {
+				t.Fatal(err)
+			}
+			g, err := NewImportGraph(ws)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := map[string]PackageKind{
+				"example.com/toolchain/a": LocalPackage,
+				"example.com/toolchain/b": LocalPackage,
+				"example.com/toolchain/c": LocalPackage,
+				"example.com/dep/d":       DependencyPackage,
+				"example.com/missing/m":   UnresolvedPackage,
+			}
+			if !maps.Equal(g.Kinds, want) {
+				t.Errorf("kinds = %v, want %v", g.Kinds, want)
+			}
+			if got := recordedArgs(rec, root); !slices.EqualFunc(got, test.wantGo, slices.Equal) {
+				t.Errorf("go commands:\n\t%q\nwant:\n\t%q", got, test.wantGo)
+			}
+		})
+	}
+}
+
+// diamond returns the graph: app -> {left, right} -> base -> shapes.
+func diamond() *ImportGraph {
+	return &ImportGraph{
+		Roots: []string{"example.com/app"},
+		Imports: map[string][]string{
+			"example.com/app":   {"example.com/left", "example.com/right"},
+			"example.com/left":  {"example.com/base"},
+			"example.com/right": {"example.com/base"},
+			"example.com/base":  {"shapes"},
+		},
+		Kinds: map[string]PackageKind{
+			"example.com/app":   LocalPackage,
+			"example.com/left":  LocalPackage,
+			"example.com/right": DependencyPackage,
+			"example.com/base":  DependencyPackage,
+			"shapes":            StdlibPackage,
+		},
+	}
+}
+
+func TestWhy(t *testing.T) {
+	tests := []struct {
+		name  string
+		match string
+		want  []string
+	}{
+		{
+			name:  "root",
+			match: "example.com/app",
+			want:  []string{"example.com/app"},
+		},
+		{
+			name:  "direct import",
+			match: "example.com/right",
+			want:  []string{"example.com/app", "example.com/right"},
+		},
+		{
+			name:  "diamond",
+			match: "example.com/base",
+			want:  []string{"example.com/app", "example.com/left", "example.com/base"},
+		},
+		{
+			name:  "stdlib",
+			match: "shapes",
+			want:  []string{"example.com/app", "example.com/left", "example.com/base", "shapes"},
+		},
+		{
+			name:  "not imported",
+			match: "example.com/other",
+		},
+	}
+	g := diamond()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := g.Why(func(pkg string) bool { return pkg == test.match })
+			if !slices.Equal(got, test.want) {
+				t.Errorf("Why(%q) = %q, want %q", test.match, got, test.want)
+			}
+		})
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	tests := []struct {
+		name string
+		g    *ImportGraph
+		want string
+	}{
+		{
+			name: "empty",
+			g:    &ImportGraph{},
+			want: `digraph gx {
+	rankdir=LR;
+}
+`,
+		},
+		{
+			name: "diamond",
+			g:    diamond(),
+			want: `digraph gx {
+	rankdir=LR;
+	"example.com/app" [shape=box, peripheries=2];
+	"example.com/base" [shape=ellipse];
+	"example.com/left" [shape=box];
+	"example.com/right" [shape=ellipse];
+	"shapes" [shape=ellipse, style=dashed];
+	"example.com/app" -> "example.com/left";
+	"example.com/app" -> "example.com/right";
+	"example.com/base" -> "shapes";
+	"example.com/left" -> "example.com/base";
+	"example.com/right" -> "example.com/base";
+}
+`,
+		},
+		{
+			name: "unresolved",
+			g: &ImportGraph{
+				Roots:   []string{"example.com/app"},
+				Imports: map[string][]string{"example.com/app": {"example.com/missing"}},
+				Kinds: map[string]PackageKind{
+					"example.com/app":     LocalPackage,
+					"example.com/missing": UnresolvedPackage,
+				},
+			},
+			want: `digraph gx {
+	rankdir=LR;
+	"example.com/app" [shape=box, peripheries=2];
+	"example.com/missing" [shape=ellipse, color=red];
+	"example.com/app" -> "example.com/missing";
+}
+`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := test.g.WriteDOT(&b); err != nil {
+				t.Fatal(err)
+			}
+			if got := b.String(); got != test.want {
+				t.Errorf("WriteDOT() =\n%s\nwant:\n%s", got, test.want)
+			}
+		})
+	}
+}
@@ -28,6 +28,8 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/gx-org/ccgx/internal/cmd/debug"
+	"github.com/gx-org/ccgx/internal/diag"
 	"github.com/gx-org/ccgx/internal/gotc"
 	"github.com/gx-org/gx/build/builder"
 	"github.com/gx-org/gx/build/importers"
@@ -81,41 +83,90 @@ func (fls *gxFiles) collectGXImports(path string, dir fs.DirEntry) error {
 	return nil
 }
 
+// walk calls fn for all the files and folders of a module in which GX
+// packages are looked for. As with the go command, folders of nested modules,
+// testdata folders, and files and folders starting with . or _ are skipped,
+// as well as the files and folders listed in the ignore file of the module.
 func (fls *gxFiles) walk(fn func(path string, dir fs.DirEntry) error) error {
-	depsPath, err := DepsPath(fls.mod)
+	root := fls.mod.Root()
+	// The folder is not created: dependency modules may be read-only.
+	depsPath := filepath.Join(root, gxdepsFolderName)
+	ignore, err := readIgnoreFile(root)
 	if err != nil {
 		return err
 	}
+	vendorPath := filepath.Join(root, "vendor")
 	walker := func(path string, dir fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
+		if path == root {
+			return fn(path, dir)
+		}
 		if strings.HasPrefix(path, depsPath) {
 			return nil
 		}
+		if path == vendorPath {
+			return filepath.SkipDir
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if skipByGoTool(dir.Name(), dir.IsDir()) || ignore.match(filepath.ToSlash(rel), dir.IsDir()) {
+			if dir.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if dir.IsDir() {
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+				// Nested module.
+				return filepath.SkipDir
+			}
+		}
 		return fn(path, dir)
 	}
-	return filepath.WalkDir(fls.mod.Root(), walker)
+	return filepath.WalkDir(root, walker)
+}
+
+// packagerInfo overrides the dependencies of a GX package
+// to import the Go packagers generated by ccgx.
+type packagerInfo struct {
+	*pkginfo.PkgInfo
+	deps []string
+}
+
+// Dependencies returns the Go packages imported by the packager.
+func (inf packagerInfo) Dependencies() []string {
+	return inf.deps
 }
 
 // packPackage a GX package.
-func packPackage(mod *gxmodule.Module, targetRoot string, pkgPath string) error {
+func packPackage(ws *Workspace, mod *gxmodule.Module, targetRoot string, pkgPath string) error {
 	pkgInfo, err := pkginfo.Load(mod, pkgPath)
 	if err != nil {
 		return err
 	}
+	info := packagerInfo{PkgInfo: pkgInfo}
+	for _, dep := range pkgInfo.Dependencies() {
+		info.deps = append(info.deps, ws.goImport(dep))
+	}
 	pkgPaths := strings.Split(pkgPath, "/")
 	targetFolder := filepath.Join(targetRoot, filepath.Join(pkgPaths...))
 	targetFile := filepath.Join(targetFolder, pkgInfo.GoPackageName()+"_gx.go")
 	if err := os.MkdirAll(filepath.Dir(targetFile), os.ModePerm); err != nil {
 		return err
 	}
-	w, err := os.Create(targetFile)
+	w, err := createFile(targetFile)
 	if err != nil {
 		return err
 	}
 	defer w.Close()
-	if err := goembed.Write(w, pkgInfo); err != nil {
+	if err := goembed.Write(w, info); err != nil {
+		return err
+	}
+	if err := w.Commit(); err != nil {
 		return err
 	}
 	for _, gxSrc := range pkgInfo.SourceFiles() {
@@ -147,7 +198,7 @@ func WriteCMakeLists(target string, pkg *ir.Package, headerPath, ccPath string)
 		pkg.Name.Name,
 		filepath.Base(ccPath),
 	)
-	return os.WriteFile(path, []byte(text), 0755)
+	return WriteFile(path, []byte(text), 0755)
 }
 
 func writeBinderSourceFile(binder bindings.File, target string, pkg *ir.Package) (string, error) {
@@ -155,7 +206,7 @@ func writeBinderSourceFile(binder bindings.File, target string, pkg *ir.Package)
 	if err := os.MkdirAll(filepath.Dir(bindingPath), 0755); err != nil {
 		return "", fmt.Errorf("cannot create target folder: %v", err)
 	}
-	f, err := os.Create(bindingPath)
+	f, err := createFile(bindingPath)
 	if err != nil {
 		return "", fmt.Errorf("cannot create target file: %v", err)
 	}
@@ -163,42 +214,135 @@ func writeBinderSourceFile(binder bindings.File, target string, pkg *ir.Package)
 	if err := binder.WriteBindings(f); err != nil {
 		return "", err
 	}
-	return bindingPath, nil
+	return bindingPath, f.Commit()
 }
 
-// BindAll writes C++ bindings for all C++ packages.
-func BindAll(mod *gxmodule.Module, fs []BinderCallback) error {
-	pkgs, err := Packages(mod)
+// BindAll writes C++ bindings for all C++ packages of a workspace.
+func BindAll(ws *Workspace, fs []BinderCallback) error {
+	depsPath, err := DepsPath(ws.Main)
 	if err != nil {
 		return err
 	}
-	if len(pkgs) == 0 {
-		return nil
-	}
-	depsPath, err := DepsPath(mod)
+	bld, deps, err := newBuilder(ws)
 	if err != nil {
 		return err
 	}
-	localImporter, err := localfs.NewWithModule(mod)
-	if err != nil {
-		return fmt.Errorf("cannot create local importer: %v", err)
-	}
-	bld := builder.New(importers.NewCacheLoader(
-		stdlib.Importer(nil),
-		localImporter,
-	))
-	for _, pkgPath := range pkgs {
-		pkg, err := bld.Build(pkgPath)
+	resolve := ws.sourceResolver(deps)
+	errs := &PackageErrors{Op: "bind"}
+	for _, mod := range ws.boundModules() {
+		pkgs, err := ws.packages(mod)
 		if err != nil {
-			return fmt.Errorf("cannot build GX package %s:\n%v\n", pkgPath, err)
+			return err
 		}
-		if err := bind(mod, pkg.IR(), depsPath, fs...); err != nil {
-			return fmt.Errorf("cannot bind package %s: %v", pkg, err)
+		for _, pkgPath := range pkgs {
+			span := debug.Start(debug.Step, "bind "+pkgPath)
+			err := bindPackage(bld, mod, pkgPath, depsPath, fs, resolve)
+			span.End(err)
+			if err := errs.add(pkgPath, err); err != nil {
+				return err
+			}
+		}
+	}
+	return errs.toError()
+}
+
+func bindPackage(bld *builder.Builder, mod *gxmodule.Module, pkgPath, depsPath string, fs []BinderCallback, resolve func(string) string) error {
+	pkg, err := bld.Build(pkgPath)
+	if err != nil {
+		return &diag.Error{
+			Msg:         fmt.Sprintf("cannot build GX package %s", pkgPath),
+			Diagnostics: diag.FromGX(err, resolve),
+			Output:      err.Error(),
 		}
 	}
+	if err := bind(mod, pkg.IR(), depsPath, fs...); err != nil {
+		return fmt.Errorf("cannot bind package %s: %v", pkgPath, err)
+	}
 	return nil
 }
 
+// sourceResolver returns a function returning the absolute path of a GX
+// source file given its path as reported by the GX builder, that is relative
+// to the root of the module of the file.
+func (ws *Workspace) sourceResolver(deps []*Dep) func(string) string {
+	var roots []string
+	for _, mod := range ws.members {
+		roots = append(roots, mod.Root())
+	}
+	for _, dep := range deps {
+		roots = append(roots, dep.Dir)
+	}
+	return func(path string) string {
+		if filepath.IsAbs(path) {
+			return path
+		}
+		for _, root := range roots {
+			abs := filepath.Join(root, filepath.FromSlash(path))
+			if _, err := os.Stat(abs); err == nil {
+				return abs
+			}
+		}
+		return path
+	}
+}
+
+// Headers returns the content of the C++ headers generated for the
+// packages of a workspace, keyed by path. Packages which have not been
+// bound yet are ignored.
+func Headers(ws *Workspace) (map[string][]byte, error) {
+	depsPath, err := DepsPath(ws.Main)
+	if err != nil {
+		return nil, err
+	}
+	headers := make(map[string][]byte)
+	for _, mod := range ws.boundModules() {
+		pkgs, err := ws.packages(mod)
+		if err != nil {
+			return nil, err
+		}
+		for _, pkgPath := range pkgs {
+			paths, err := filepath.Glob(filepath.Join(depsPath, filepath.FromSlash(pkgPath), "*.h"))
+			if err != nil {
+				return nil, err
+			}
+			for _, path := range paths {
+				data, err := os.ReadFile(path)
+				if err != nil {
+					return nil, err
+				}
+				headers[path] = data
+			}
+		}
+	}
+	return headers, nil
+}
+
+// newBuilder returns a GX builder importing packages from the modules of a workspace
+// and from their dependencies. The dependencies are also returned.
+func newBuilder(ws *Workspace) (*builder.Builder, []*Dep, error) {
+	imps := []importers.Importer{stdlib.Importer(nil)}
+	deps, err := Deps(ws)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, dep := range deps {
+		if dep.GXFiles == 0 {
+			continue
+		}
+		imps = append(imps, depImporter{dep: dep})
+	}
+	var depImps []importers.Importer
+	for _, mod := range ws.Modules {
+		localImporter, err := localfs.NewWithModule(mod)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot create local importer: %v", err)
+		}
+		imps = append(imps, workspaceImporter{Importer: localImporter, ws: ws})
+		depImps = append(depImps, localImporter)
+	}
+	return builder.New(importers.NewCacheLoader(append(imps, depImps...)...)), deps, nil
+}
+
 func bind(mod *gxmodule.Module, pkg *ir.Package, depsPath string, fs ...BinderCallback) error {
 	bnd, err := ccbindings.New(pkg)
 	if err != nil {
@@ -242,41 +386,133 @@ func Packages(mod *gxmodule.Module) ([]string, error) {
 
 const packagerFolderName = "packager"
 
-// PackAll looks for all GX packages and generates a matching Go package to encapsulte the GX source code.
-func PackAll(mod *gxmodule.Module) error {
-	pkgs, err := Packages(mod)
+// PackAll looks for all GX packages of a workspace and generates a matching Go package
+// to encapsulte the GX source code.
+func PackAll(ws *Workspace) error {
+	errs := &PackageErrors{Op: "pack"}
+	for _, mod := range ws.boundModules() {
+		span := debug.Start(debug.Step, "pack "+mod.Name())
+		err := packModule(ws, mod, errs)
+		span.End(err)
+		if err != nil {
+			return err
+		}
+	}
+	return errs.toError()
+}
+
+func packModule(ws *Workspace, mod *gxmodule.Module, errs *PackageErrors) error {
+	pkgs, err := ws.packages(mod)
 	if err != nil {
 		return err
 	}
-	packagerRoot, err := DepsPath(mod)
+	packagerRoot, err := DepsPath(ws.packagerModule(mod))
 	if err != nil {
 		return err
 	}
 	packagerRoot = filepath.Join(packagerRoot, packagerFolderName)
 	for _, pkg := range pkgs {
-		if err := packPackage(mod, packagerRoot, pkg); err != nil {
+		if err := errs.add(pkg, packPackage(ws, mod, packagerRoot, pkg)); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func installLinkToModule(cache *gotc.Cache, targetPath string, dep *gomodule.Version) error {
-	gxModPath, err := cache.OSPath(dep)
-	if err != nil {
-		return err
+// LinkMode specifies how dependencies are installed in gxdeps.
+type LinkMode string
+
+const (
+	// LinkAbsolute creates symbolic links with absolute paths.
+	LinkAbsolute LinkMode = "absolute"
+	// LinkRelative creates symbolic links with paths relative to gxdeps.
+	LinkRelative LinkMode = "relative"
+	// LinkCopy copies the dependencies, using hard links when possible.
+	LinkCopy LinkMode = "copy"
+)
+
+var linkModes = []LinkMode{LinkAbsolute, LinkRelative, LinkCopy}
+
+// String returns the name of the mode.
+func (mode *LinkMode) String() string {
+	return string(*mode)
+}
+
+// Set the mode from its name.
+func (mode *LinkMode) Set(s string) error {
+	if !slices.Contains(linkModes, LinkMode(s)) {
+		return fmt.Errorf("invalid link mode %q: must be one of %v", s, linkModes)
 	}
-	targetLink := filepath.Join(targetPath, dep.Path)
+	*mode = LinkMode(s)
+	return nil
+}
+
+// Type returns the type of the flag value.
+func (mode *LinkMode) Type() string {
+	return "mode"
+}
+
+func installLinkToModule(targetPath, modPath, modDir string, mode LinkMode) (err error) {
+	targetLink := filepath.Join(targetPath, modPath)
+	span := debug.Start(debug.Write, targetLink).Set("source", modDir).Set("mode", string(mode))
+	defer func() { span.End(err) }()
 	folder := filepath.Dir(targetLink)
 	if err := os.MkdirAll(folder, 0755); err != nil {
 		return err
 	}
-	if _, err := os.Stat(targetLink); err == nil {
-		if err := os.Remove(targetLink); err != nil {
+	if mode == LinkCopy {
+		return installCopyOfModule(targetLink, modDir)
+	}
+	if err := removeAll(targetLink); err != nil {
+		return err
+	}
+	if mode == LinkRelative {
+		relDir, err := filepath.Rel(folder, modDir)
+		if err != nil {
 			return err
 		}
+		return os.Symlink(relDir, targetLink)
+	}
+	return os.Symlink(modDir, targetLink)
+}
+
+// installCopyOfModule copies a module in a temporary folder next to
+// targetLink, then renames it to targetLink. An interrupted copy never
+// leaves a partial module in gxdeps.
+func installCopyOfModule(targetLink, modDir string) error {
+	tmp, err := os.MkdirTemp(filepath.Dir(targetLink), "."+filepath.Base(targetLink)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	if err := os.Chmod(tmp, 0755); err != nil {
+		return err
+	}
+	if err := copyDir(modDir, tmp, skipModuleEntry); err != nil {
+		return err
+	}
+	if err := removeAll(targetLink); err != nil {
+		return err
+	}
+	return os.Rename(tmp, targetLink)
+}
+
+// removeAll removes a file, a link, or a folder if it exists.
+func removeAll(path string) error {
+	if _, err := os.Lstat(path); err != nil {
+		return nil
+	}
+	return os.RemoveAll(path)
+}
+
+// removeLinkToModule removes a link previously created by installLinkToModule.
+func removeLinkToModule(targetPath, modPath string) error {
+	targetLink := filepath.Join(targetPath, modPath)
+	info, err := os.Lstat(targetLink)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return nil
 	}
-	return os.Symlink(gxModPath, targetLink)
+	return os.Remove(targetLink)
 }
 
 const gxdepsFolderName = "gxdeps"
@@ -291,63 +527,174 @@ func DepsPath(mod *gxmodule.Module) (string, error) {
 	return depsPath, nil
 }
 
-// LinkAllDeps creates links to dependencies.
-// Returns the path where the links where created.
-func LinkAllDeps(mod *gxmodule.Module, cache *gotc.Cache) error {
-	if err := gotc.ModTidy(); err != nil {
+// ModTidy updates the requirements of the modules of a workspace.
+// It runs go mod tidy or, in a Go workspace, go work sync.
+// When running offline, it first checks that all the modules required
+// by the modules of the workspace are in the module cache.
+// The modules in ws.Require are then required again by the main module.
+func ModTidy(ws *Workspace) (err error) {
+	span := debug.Start(debug.Step, "tidy")
+	defer func() { span.End(err) }()
+	if gotc.Offline {
+		cache, err := gotc.NewCache()
+		if err != nil {
+			return err
+		}
+		if err := gotc.CheckCache(cache, ws.requirements()); err != nil {
+			return err
+		}
+	}
+	if ws.WorkFile != "" {
+		err = gotc.WorkSync()
+	} else {
+		err = gotc.ModTidy()
+	}
+	if err != nil || len(ws.Require) == 0 {
+		return err
+	}
+	// go mod tidy removes the modules which are not imported yet.
+	args := make([]string, len(ws.Require))
+	for i, req := range ws.Require {
+		args[i] = req.String()
+	}
+	return gotc.Get(ws.Main.Root(), args...)
+}
+
+// requirements returns the modules required by the modules of a workspace,
+// excluding the modules of the workspace and modules replaced by local folders.
+func (ws *Workspace) requirements() []gomodule.Version {
+	var reqs []gomodule.Version
+	for _, mod := range ws.Modules {
+		replaces := make(map[string]gomodule.Version)
+		for _, rep := range mod.File().Replace {
+			replaces[rep.Old.Path] = rep.New
+		}
+		for _, req := range mod.File().Require {
+			if ws.member(req.Mod.Path) != nil {
+				continue
+			}
+			dep := req.Mod
+			if rep, ok := replaces[dep.Path]; ok {
+				if rep.Version == "" {
+					// Replaced by a local folder.
+					continue
+				}
+				dep = rep
+			}
+			reqs = append(reqs, dep)
+		}
+	}
+	return reqs
+}
+
+// syncDeps updates the requirements of the modules of a workspace
+// unless its dependencies are vendored.
+func syncDeps(ws *Workspace) error {
+	if gotc.VendorDir(ws.Main.Root()) != "" {
+		// Run ccgx mod vendor to update vendored dependencies.
+		return nil
+	}
+	if gotc.ReadOnly {
+		// go.mod and go.sum are used as is.
+		return nil
+	}
+	return ModTidy(ws)
+}
+
+// LinkAllDeps creates links to the dependencies of all the modules of a workspace.
+// Only dependencies with GX source files or C/C++ headers are linked.
+// Modules of the workspace which are not bound are linked from their source folder.
+func LinkAllDeps(ws *Workspace, mode LinkMode) (err error) {
+	span := debug.Start(debug.Step, "link")
+	defer func() { span.End(err) }()
+	if err := syncDeps(ws); err != nil {
+		return err
+	}
+	depsPath, err := DepsPath(ws.Main)
+	if err != nil {
 		return err
 	}
-	depsPath, err := DepsPath(mod)
+	deps, err := Deps(ws)
 	if err != nil {
 		return err
 	}
-	for _, dep := range mod.Deps() {
-		if err := installLinkToModule(cache, depsPath, dep); err != nil {
+	for _, dep := range deps {
+		if !dep.Linked() {
+			if err := removeLinkToModule(depsPath, dep.Path); err != nil {
+				return err
+			}
+			continue
+		}
+		depMode := mode
+		if ws.isBoundDependency(dep.Path) {
+			// Bindings are written in the folder of the module:
+			// it cannot be a link to the read-only module cache.
+			depMode = LinkCopy
+		}
+		if err := installLinkToModule(depsPath, dep.Path, dep.Dir, depMode); err != nil {
 			return err
 		}
 	}
-	return err
+	return nil
+}
+
+// packagerPath returns the path of the Go package generated to encapsulate a GX package.
+func packagerPath(mod *gxmodule.Module, gxPkg string) string {
+	return (mod.Name() + "/" +
+		gxdepsFolderName + "/" +
+		packagerFolderName + "/" +
+		gxPkg)
 }
 
-func listGoPackager(mod *gxmodule.Module) ([]string, error) {
-	gxPackages, err := Packages(mod)
+func listGoPackager(ws *Workspace, mod *gxmodule.Module) ([]string, error) {
+	gxPackages, err := ws.packages(mod)
 	if err != nil {
 		return nil, err
 	}
 	packagers := make([]string, len(gxPackages))
 	for i, gxPkg := range gxPackages {
-		packagers[i] = (mod.Name() + "/" +
-			gxdepsFolderName + "/" +
-			packagerFolderName + "/" +
-			gxPkg)
+		packagers[i] = packagerPath(ws.packagerModule(mod), gxPkg)
 	}
 	return packagers, nil
 }
 
-func writeGoSource(mod *gxmodule.Module, path, name string) (string, error) {
-	files := gxFiles{
-		mod: mod,
-		list: []string{
-			"github.com/gx-org/gx/golang/binder/cgx",
-			"github.com/gx-org/xlapjrt/cgx",
-		},
+func writeGoSource(ws *Workspace, backend, path, name string) (string, error) {
+	imports := []string{
+		"github.com/gx-org/gx/golang/binder/cgx",
+		backend,
 	}
-	if err := files.walk(files.collectGXImports); err != nil {
-		return "", err
-	}
-	goPackagers, err := listGoPackager(mod)
-	if err != nil {
-		return "", err
+	for _, mod := range ws.boundModules() {
+		goPackagers, err := listGoPackager(ws, mod)
+		if err != nil {
+			return "", err
+		}
+		if TrimImports {
+			imports = append(imports, goPackagers...)
+			continue
+		}
+		pkgImports, err := packageImports(mod)
+		if err != nil {
+			return "", err
+		}
+		for pkg, imps := range pkgImports {
+			if ws.selected != nil && !ws.selected[pkg] {
+				continue
+			}
+			for _, imp := range imps {
+				imports = append(imports, ws.goImport(imp))
+			}
+		}
+		imports = append(imports, goPackagers...)
 	}
-	files.list = append(files.list, goPackagers...)
-	deps := unique(files.list)
-	var imports strings.Builder
+	deps := unique(imports)
+	sort.Strings(deps)
+	var importsSrc strings.Builder
 	std := stdlib.Importer(nil)
 	for _, dep := range deps {
 		if std.Support(dep) {
 			continue
 		}
-		fmt.Fprintf(&imports, "import _ %s\n", strconv.Quote(dep))
+		fmt.Fprintf(&importsSrc, "import _ %s\n", strconv.Quote(dep))
 	}
 	cArchiveSource := fmt.Sprintf(`package main
 
@@ -363,31 +710,97 @@ func InitGX() {
 }
 
 func main() {}
-`, imports.String())
+`, importsSrc.String())
 	srcFile := filepath.Join(path, name+".go")
-	return srcFile, os.WriteFile(srcFile, []byte(cArchiveSource), 0644)
+	return srcFile, WriteFile(srcFile, []byte(cArchiveSource), 0644)
 }
 
 const basename string = "carchive"
 
-// CompileCArchive creates a Go file with all the GX/Go dependencies and
-// a main function. This file is then compiled into a static binary C library.
-func CompileCArchive(mod *gxmodule.Module) error {
-	path, err := DepsPath(mod)
+// WriteCArchiveSource writes the Go source files of the C archives of a
+// workspace in the gxdeps folder of the main module.
+func WriteCArchiveSource(ws *Workspace) error {
+	_, err := writeCArchiveSources(ws)
+	return err
+}
+
+// archive is a C archive to compile.
+type archive struct {
+	target *Target
+	ws     *Workspace
+	// src is the path of the Go source of the archive.
+	src string
+}
+
+func writeCArchiveSources(ws *Workspace) ([]archive, error) {
+	depsPath, err := DepsPath(ws.Main)
+	if err != nil {
+		return nil, err
+	}
+	if len(ws.Targets) == 0 {
+		src, err := writeGoSource(ws, DefaultBackend, depsPath, basename)
+		if err != nil {
+			return nil, err
+		}
+		return []archive{{ws: ws, src: src}}, nil
+	}
+	var archives []archive
+	for _, target := range ws.Targets {
+		path := filepath.Join(depsPath, target.Name)
+		if err := os.MkdirAll(path, 0755); err != nil {
+			return nil, err
+		}
+		src, err := writeGoSource(target.ws, target.Backend, path, basename)
+		if err != nil {
+			return nil, err
+		}
+		archives = append(archives, archive{target: target, ws: target.ws, src: src})
+	}
+	return archives, nil
+}
+
+// CompileCArchive creates a Go file with all the GX/Go dependencies of a
+// workspace and a main function. This file is then compiled into a static
+// binary C library. If the workspace has targets, one library is built
+// per target in gxdeps/<target> with a CMakeLists.txt.
+func CompileCArchive(ws *Workspace) (err error) {
+	span := debug.Start(debug.Step, "carchive")
+	defer func() { span.End(err) }()
+	depsPath, err := DepsPath(ws.Main)
 	if err != nil {
 		return err
 	}
-	src, err := writeGoSource(mod, path, basename)
+	archives, err := writeCArchiveSources(ws)
 	if err != nil {
 		return err
 	}
-	if err := gotc.ModTidy(); err != nil {
+	if err := syncDeps(ws); err != nil {
 		return err
 	}
+	for _, ar := range archives {
+		if err := compileArchive(ws, ar, depsPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func compileArchive(ws *Workspace, ar archive, depsPath string) (err error) {
+	if ar.target != nil {
+		span := debug.Start(debug.Step, "carchive "+ar.target.Name)
+		defer func() { span.End(err) }()
+	}
+	path := filepath.Dir(ar.src)
 	cArchivePath := filepath.Join(path, basename+".a")
-	if err := gotc.BuildArchive(mod.Root(), src, cArchivePath); err != nil {
+	if err := gotc.BuildArchive(ws.Main.Root(), ar.src, cArchivePath); err != nil {
 		return err
 	}
 	cHeaderPath := filepath.Join(path, basename+".h")
-	return gotc.BuildCGoHeader(mod.Root(), src, cHeaderPath)
+	if err := gotc.BuildCGoHeader(ws.Main.Root(), ar.src, cHeaderPath); err != nil {
+		return err
+	}
+	if ar.target == nil {
+		return nil
+	}
+	return writeTargetCMakeLists(ar.target, depsPath)
 }
@@ -0,0 +1,189 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gxtc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gx-org/ccgx/internal/gotc"
+	gxmodule "github.com/gx-org/gx/build/module"
+	gomodule "golang.org/x/mod/module"
+)
+
+// fakeGo is a toolchain writing canned outputs instead of running go.
+type fakeGo struct {
+	// stdout is the standard output of the commands, by go subcommand.
+	stdout map[string]string
+}
+
+func (f fakeGo) Run(ctx context.Context, cmd *gotc.Command) error {
+	if out, ok := f.stdout[cmd.Args[0]]; ok && cmd.Stdout != nil {
+		if _, err := io.WriteString(cmd.Stdout, out); err != nil {
+			return err
+		}
+	}
+	// Create the files written by go build and go tool cgo.
+	for i, arg := range cmd.Args[:len(cmd.Args)-1] {
+		if arg == "-o" || arg == "-exportheader" {
+			if err := os.WriteFile(cmd.Args[i+1], nil, 0644); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// newTestWorkspace returns the workspace of a copy of tests/toolchain
+// in which go commands are recorded.
+func newTestWorkspace(t *testing.T) (*Workspace, *gotc.Recorder) {
+	t.Helper()
+	t.Setenv("GOWORK", "off")
+	root := filepath.Join(t.TempDir(), "toolchain")
+	if err := copyDir(filepath.Join("..", "..", "tests", "toolchain"), root, func(fs.DirEntry) bool { return false }); err != nil {
+		t.Fatal(err)
+	}
+	mod, err := gxmodule.New(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws, err := NewWorkspace(mod, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := fmt.Sprintf(`{"Path": "example.com/dep", "Version": "v1.0.0", "Dir": %q}`, filepath.Join(root, "dep"))
+	rec := &gotc.Recorder{Toolchain: fakeGo{stdout: map[string]string{"list": list}}}
+	prev := gotc.Go
+	gotc.Go = rec
+	t.Cleanup(func() { gotc.Go = prev })
+	return ws, rec
+}
+
+// recordedArgs returns the arguments of the recorded commands. Paths are
+// relative to root and the temporary build folders are removed.
+func recordedArgs(rec *gotc.Recorder, root string) [][]string {
+	var cmds [][]string
+	for _, cmd := range rec.Commands() {
+		args := slices.Clone(cmd.Args)
+		for i, arg := range args {
+			rel, err := filepath.Rel(root, arg)
+			if !filepath.IsAbs(arg) || err != nil {
+				continue
+			}
+			elems := slices.DeleteFunc(strings.Split(filepath.ToSlash(rel), "/"), func(elem string) bool {
+				return strings.HasPrefix(elem, ".ccgx-build-")
+			})
+			args[i] = strings.Join(elems, "/")
+		}
+		cmds = append(cmds, args)
+	}
+	return cmds
+}
+
+func TestGoCommands(t *testing.T) {
+	tidy := []string{"mod", "tidy"}
+	list := []string{"list", "-m", "-e", "-json", "example.com/dep"}
+	tests := []struct {
+		name  string
+		setup func(*testing.T, *Workspace)
+		run   func(*Workspace) error
+		want  [][]string
+	}{
+		{
+			name: "ModTidy",
+			run:  ModTidy,
+			want: [][]string{tidy},
+		},
+		{
+			name: "ModTidy/require",
+			setup: func(t *testing.T, ws *Workspace) {
+				ws.Require = []gomodule.Version{{Path: "example.com/gxlib", Version: "v1.2.0"}}
+			},
+			run: ModTidy,
+			want: [][]string{
+				tidy,
+				{"get", "example.com/gxlib@v1.2.0"},
+			},
+		},
+		{
+			name: "LinkAllDeps",
+			run: func(ws *Workspace) error {
+				return LinkAllDeps(ws, LinkAbsolute)
+			},
+			want: [][]string{tidy, list},
+		},
+		{
+			name: "LinkAllDeps/readonly",
+			setup: func(t *testing.T, ws *Workspace) {
+				gotc.ReadOnly = true
+				t.Cleanup(func() { gotc.ReadOnly = false })
+			},
+			run: func(ws *Workspace) error {
+				return LinkAllDeps(ws, LinkAbsolute)
+			},
+			want: [][]string{list},
+		},
+		{
+			name: "CompileCArchive",
+			run:  CompileCArchive,
+			want: [][]string{
+				tidy,
+				{"build", "-buildmode=c-archive", "-o", "gxdeps/carchive.a", "gxdeps/carchive.go"},
+				{"tool", "cgo", "-objdir", "gxdeps/_obj", "-exportheader", "gxdeps/carchive.h", "gxdeps/carchive.go"},
+			},
+		},
+		{
+			name: "CompileCArchive/targets",
+			setup: func(t *testing.T, ws *Workspace) {
+				if err := ws.AddTarget("server", []string{"./a"}, ""); err != nil {
+					t.Fatal(err)
+				}
+				if err := ws.AddTarget("tool", []string{"./b"}, ""); err != nil {
+					t.Fatal(err)
+				}
+			},
+			run: CompileCArchive,
+			want: [][]string{
+				tidy,
+				{"build", "-buildmode=c-archive", "-o", "gxdeps/server/carchive.a", "gxdeps/server/carchive.go"},
+				{"tool", "cgo", "-objdir", "gxdeps/server/_obj", "-exportheader", "gxdeps/server/carchive.h", "gxdeps/server/carchive.go"},
+				{"build", "-buildmode=c-archive", "-o", "gxdeps/tool/carchive.a", "gxdeps/tool/carchive.go"},
+				{"tool", "cgo", "-objdir", "gxdeps/tool/_obj", "-exportheader", "gxdeps/tool/carchive.h", "gxdeps/tool/carchive.go"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ws, rec := newTestWorkspace(t)
+			if test.setup != nil {
+				test.setup(t, ws)
+			}
+			if err := test.run(ws); err != nil {
+				t.Fatalf("%s: %v", test.name, err)
+			}
+			got := recordedArgs(rec, ws.Main.Root())
+			if !slices.EqualFunc(got, test.want, slices.Equal) {
+				t.Errorf("%s: go commands:\n\t%q\nwant:\n\t%q", test.name, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gxtc
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFileName is the name of the file listing the files and folders
+// of a module in which ccgx does not look for GX packages.
+const IgnoreFileName = ".ccgxignore"
+
+// ignoreRule is a line of an ignore file.
+type ignoreRule struct {
+	pattern string
+	// anchored rules match the path relative to the root of the module.
+	// Other rules match the base name of files and folders.
+	anchored bool
+	dirOnly  bool
+}
+
+type ignoreRules []ignoreRule
+
+// readIgnoreFile reads the ignore file at the root of a module.
+// Each line is a pattern (see path.Match) matching the name of files or
+// folders, or their path relative to the root if the pattern contains
+// a slash. A trailing slash only matches folders. Empty lines and lines
+// starting with # are ignored.
+func readIgnoreFile(root string) (ignoreRules, error) {
+	fileName := filepath.Join(root, IgnoreFileName)
+	data, err := os.ReadFile(fileName)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var rules ignoreRules
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		rule := ignoreRule{}
+		rule.dirOnly = strings.HasSuffix(text, "/")
+		text = strings.TrimSuffix(text, "/")
+		rule.anchored = strings.Contains(text, "/")
+		rule.pattern = strings.TrimPrefix(text, "/")
+		if _, err := path.Match(rule.pattern, ""); err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid pattern %q: %v", fileName, line, text, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, scanner.Err()
+}
+
+// match returns true if a file or folder, given by its slash-separated
+// path relative to the root of the module, is ignored.
+func (rules ignoreRules) match(rel string, isDir bool) bool {
+	for _, rule := range rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		name := path.Base(rel)
+		if rule.anchored {
+			name = rel
+		}
+		if ok, _ := path.Match(rule.pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// skipByGoTool returns true if the go command ignores a file or folder.
+func skipByGoTool(name string, isDir bool) bool {
+	if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+		return true
+	}
+	return isDir && name == "testdata"
+}
@@ -0,0 +1,117 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gxtc
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReadIgnoreFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		noFile  bool
+		content string
+		want    ignoreRules
+		wantErr string
+	}{
+		{name: "no file", noFile: true},
+		{
+			name:    "comments and empty lines",
+			content: "# generated files\n\n   \n",
+		},
+		{
+			name:    "rules",
+			content: "*.tmp\n  build/  \n/docs\nexamples/*/out\nthird_party/\n",
+			want: ignoreRules{
+				{pattern: "*.tmp"},
+				{pattern: "build", dirOnly: true},
+				{pattern: "docs", anchored: true},
+				{pattern: "examples/*/out", anchored: true},
+				{pattern: "third_party", dirOnly: true},
+			},
+		},
+		{
+			name:    "invalid pattern",
+			content: "ok\n[a-\n",
+			wantErr: IgnoreFileName + `:2: invalid pattern "[a-"`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := t.TempDir()
+			if !test.noFile {
+				if err := os.WriteFile(filepath.Join(root, IgnoreFileName), []byte(test.content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := readIgnoreFile(root)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("readIgnoreFile() error = %v, want %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("readIgnoreFile() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestIgnoreRulesMatch(t *testing.T) {
+	rules := ignoreRules{
+		{pattern: "*.tmp"},
+		{pattern: "build", dirOnly: true},
+		{pattern: "docs", anchored: true},
+		{pattern: "examples/*/out", anchored: true},
+	}
+	tests := []struct {
+		rel   string
+		isDir bool
+		want  bool
+	}{
+		// Base name rules match at any depth.
+		{rel: "a.tmp", want: true},
+		{rel: "pkg/sub/a.tmp", want: true},
+		{rel: "pkg/a.tmpl"},
+		// Folder rules do not match files.
+		{rel: "build", isDir: true, want: true},
+		{rel: "pkg/build", isDir: true, want: true},
+		{rel: "build"},
+		// Anchored rules match the path from the root.
+		{rel: "docs", isDir: true, want: true},
+		{rel: "docs", want: true},
+		{rel: "pkg/docs", isDir: true},
+		{rel: "examples/hello/out", isDir: true, want: true},
+		{rel: "examples/hello/sub/out", isDir: true},
+		{rel: "pkg/examples/hello/out", isDir: true},
+		{rel: "pkg/a.gx"},
+	}
+	for _, test := range tests {
+		if got := rules.match(test.rel, test.isDir); got != test.want {
+			t.Errorf("match(%q, %v) = %v, want %v", test.rel, test.isDir, got, test.want)
+		}
+	}
+	if ignoreRules(nil).match("a.tmp", false) {
+		t.Errorf("no rule matched a.tmp")
+	}
+}
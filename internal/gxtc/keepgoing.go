@@ -0,0 +1,76 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gxtc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// KeepGoing processes all the packages even if some of them fail.
+// The errors of all the failed packages are then returned together.
+var KeepGoing bool
+
+// PackageErrors are the errors of the packages which failed
+// when KeepGoing is set.
+type PackageErrors struct {
+	// Op is the operation which failed (e.g. pack or bind).
+	Op string
+	// Total is the number of packages processed.
+	Total int
+	// Pkgs are the packages which failed.
+	Pkgs []string
+	// Errs are the errors of the packages which failed.
+	Errs []error
+}
+
+// add records the result of processing a package.
+// Returns the error of the package if processing needs to stop.
+func (errs *PackageErrors) add(pkg string, err error) error {
+	errs.Total++
+	if err == nil {
+		return nil
+	}
+	if !KeepGoing {
+		return err
+	}
+	errs.Pkgs = append(errs.Pkgs, pkg)
+	errs.Errs = append(errs.Errs, err)
+	return nil
+}
+
+// toError returns nil if no package failed.
+func (errs *PackageErrors) toError() error {
+	if len(errs.Errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// Error returns the errors of all the packages followed by a summary.
+func (errs *PackageErrors) Error() string {
+	var lines []string
+	for _, err := range errs.Errs {
+		lines = append(lines, err.Error())
+	}
+	lines = append(lines, fmt.Sprintf("%d of %d GX packages failed to %s:\n\t%s",
+		len(errs.Errs), errs.Total, errs.Op, strings.Join(errs.Pkgs, "\n\t")))
+	return strings.Join(lines, "\n")
+}
+
+// Unwrap returns the errors of the packages.
+func (errs *PackageErrors) Unwrap() []error {
+	return errs.Errs
+}
@@ -0,0 +1,160 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gxtc
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/gx-org/ccgx/internal/diag"
+	"github.com/gx-org/gx/build/builder"
+	"github.com/gx-org/gx/golang/binder/ccbindings"
+)
+
+// PackageInfo describes a GX package of a workspace.
+type PackageInfo struct {
+	// ImportPath is the path of the package.
+	ImportPath string `json:"importPath"`
+	// Module is the path of the module of the package.
+	Module string `json:"module"`
+	// Dir is the folder of the package.
+	Dir string `json:"dir"`
+	// GXFiles are the names of the GX source files in Dir.
+	GXFiles []string `json:"gxFiles"`
+	// Imports are the GX packages imported by the package.
+	Imports []string `json:"imports,omitempty"`
+	// Packager is the path of the Go package generated to embed the package.
+	Packager string `json:"packager"`
+	// Header is the path of the generated C++ header.
+	Header string `json:"header,omitempty"`
+	// CCFile is the path of the generated C++ source file.
+	CCFile string `json:"ccFile,omitempty"`
+	// Funcs are the exported functions of the package.
+	Funcs []FuncInfo `json:"funcs,omitempty"`
+	// Error is set if the package cannot be built.
+	Error string `json:"error,omitempty"`
+}
+
+// FuncInfo describes an exported GX function.
+type FuncInfo struct {
+	// Name of the function.
+	Name string `json:"name"`
+	// Signature of the function.
+	Signature string `json:"signature"`
+	// Doc is the documentation of the function.
+	Doc string `json:"doc,omitempty"`
+}
+
+// List returns the selected GX packages of a workspace. If build is set,
+// the packages are built to find the paths of the generated C++ files and
+// the exported functions. Packages which fail to build have Error set.
+func List(ws *Workspace, build bool) ([]*PackageInfo, error) {
+	var bld *builder.Builder
+	var resolve func(string) string
+	var depsPath string
+	if build {
+		b, deps, err := newBuilder(ws)
+		if err != nil {
+			return nil, err
+		}
+		bld, resolve = b, ws.sourceResolver(deps)
+		// The folder is not created: list does not write anything.
+		depsPath = filepath.Join(ws.Main.Root(), gxdepsFolderName)
+	}
+	var infos []*PackageInfo
+	for _, mod := range ws.boundModules() {
+		pkgs, err := ws.packages(mod)
+		if err != nil {
+			return nil, err
+		}
+		imports, err := packageImports(mod)
+		if err != nil {
+			return nil, err
+		}
+		for _, pkgPath := range pkgs {
+			dir, err := mod.ImportToOSPath(pkgPath)
+			if err != nil {
+				return nil, err
+			}
+			info := &PackageInfo{
+				ImportPath: pkgPath,
+				Module:     mod.Name(),
+				Dir:        filepath.Clean(dir),
+				Imports:    imports[pkgPath],
+				Packager:   packagerPath(ws.packagerModule(mod), pkgPath),
+			}
+			if info.GXFiles, err = gxSourceFiles(dir); err != nil {
+				return nil, err
+			}
+			if bld != nil {
+				info.describe(bld, resolve, depsPath)
+			}
+			infos = append(infos, info)
+		}
+	}
+	return infos, nil
+}
+
+// gxSourceFiles returns the names of the GX source files in a folder.
+func gxSourceFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".gx" || skipByGoTool(name, false) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// describe builds a package to set the paths of its generated C++ files
+// and its exported functions.
+func (info *PackageInfo) describe(bld *builder.Builder, resolve func(string) string, depsPath string) {
+	pkg, err := bld.Build(info.ImportPath)
+	if err != nil {
+		info.Error = (&diag.Error{
+			Msg:         "cannot build GX package " + info.ImportPath,
+			Diagnostics: diag.FromGX(err, resolve),
+			Output:      err.Error(),
+		}).Error()
+		return
+	}
+	irPkg := pkg.IR()
+	for fn := range irPkg.ExportedFuncs() {
+		fInfo := FuncInfo{
+			Name:      fn.Name(),
+			Signature: fn.FuncType().NameString(fn.Name()),
+		}
+		if doc := fn.Doc(); doc != nil {
+			fInfo.Doc = doc.Text()
+		}
+		info.Funcs = append(info.Funcs, fInfo)
+	}
+	bnd, err := ccbindings.New(irPkg)
+	if err != nil {
+		info.Error = err.Error()
+		return
+	}
+	files := bnd.Files()
+	info.Header = files[0].BuildFilePath(depsPath, irPkg)
+	info.CCFile = files[1].BuildFilePath(depsPath, irPkg)
+}
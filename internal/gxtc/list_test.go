@@ -0,0 +1,89 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gxtc
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestGXSourceFiles(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  []string
+	}{
+		{
+			name:  "sorted",
+			files: []string{"b.gx", "a.gx"},
+			want:  []string{"a.gx", "b.gx"},
+		},
+		{
+			name:  "other files",
+			files: []string{"a.gx", "a.go", "a.h", "gx"},
+			want:  []string{"a.gx"},
+		},
+		{
+			name:  "ignored by the go tool",
+			files: []string{"a.gx", "_b.gx", ".c.gx"},
+			want:  []string{"a.gx"},
+		},
+		{
+			name:  "folders",
+			files: []string{"a.gx", "sub.gx/", "sub/b.gx"},
+			want:  []string{"a.gx"},
+		},
+		{
+			name:  "empty",
+			files: []string{"sub/a.gx"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, file := range test.files {
+				path := filepath.Join(dir, filepath.FromSlash(file))
+				if strings.HasSuffix(file, "/") {
+					if err := os.MkdirAll(path, 0755); err != nil {
+						t.Fatal(err)
+					}
+					continue
+				}
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte("package p\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := gxSourceFiles(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("gxSourceFiles() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGXSourceFilesNotExist(t *testing.T) {
+	if _, err := gxSourceFiles(filepath.Join(t.TempDir(), "missing")); !os.IsNotExist(err) {
+		t.Errorf("gxSourceFiles() error = %v, want a not exist error", err)
+	}
+}
@@ -17,7 +17,11 @@ package gxtc
 import (
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
+
+	"github.com/gx-org/ccgx/internal/cmd/debug"
 )
 
 // copy a file from src to dst.
@@ -54,18 +58,114 @@ func copyContent(src, dst string) error {
 		return fmt.Errorf("cannot open source %s: %v", src, err)
 	}
 	defer in.Close()
-	out, err := os.Create(dst)
+	out, err := createFile(dst)
 	if err != nil {
 		return fmt.Errorf("cannot destination %s: %v", dst, err)
 	}
+	defer out.Close()
+	if _, err = io.Copy(out, in); err != nil {
+		return fmt.Errorf("copy error: %v", err)
+	}
+	if err := out.Sync(); err != nil {
+		return err
+	}
+	return out.Commit()
+}
+
+// copyDir copies the regular files of a folder recursively.
+// Entries for which skip returns true are not copied.
+func copyDir(src, dst string, skip func(fs.DirEntry) bool) error {
+	return filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != src && skip(entry) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if entry.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		return copy(path, target)
+	})
+}
+
+// atomicFile is a file being written and traced until it is committed.
+// The content is written in a temporary file which replaces the file
+// when committed so that an interrupted write never leaves a partial file.
+type atomicFile struct {
+	*os.File
+	path string
+	perm os.FileMode
+	span *debug.Span
+	done bool
+}
+
+// createFile creates a file like os.Create and traces its writing.
+// The file is only written if Commit is called.
+func createFile(path string) (*atomicFile, error) {
+	span := debug.Start(debug.Write, path)
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		span.End(err)
+		return nil, err
+	}
+	return &atomicFile{File: f, path: path, perm: 0644, span: span}, nil
+}
+
+// Commit closes the file and replaces the target file with its content.
+func (f *atomicFile) Commit() (err error) {
+	if f.done {
+		return nil
+	}
+	f.done = true
 	defer func() {
-		cerr := out.Close()
-		if err == nil {
-			err = cerr
+		if err != nil {
+			os.Remove(f.Name())
 		}
+		f.span.End(err)
 	}()
-	if _, err = io.Copy(out, in); err != nil {
-		return fmt.Errorf("copy error: %v", err)
+	if err := f.File.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), f.perm); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), f.path)
+}
+
+// Close discards the content of the file if it has not been committed.
+func (f *atomicFile) Close() error {
+	if f.done {
+		return nil
+	}
+	f.done = true
+	err := f.File.Close()
+	os.Remove(f.Name())
+	f.span.End(fmt.Errorf("%s not written", f.path))
+	return err
+}
+
+// WriteFile writes a file like os.WriteFile and traces it.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	f, err := createFile(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		return err
 	}
-	return out.Sync()
+	f.perm = perm
+	return f.Commit()
 }
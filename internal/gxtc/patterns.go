@@ -0,0 +1,325 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gxtc
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	gxmodule "github.com/gx-org/gx/build/module"
+)
+
+// packageImports returns the GX imports of all the GX packages of a module.
+func packageImports(mod *gxmodule.Module) (map[string][]string, error) {
+	imports := make(map[string][]string)
+	files := gxFiles{mod: mod}
+	err := files.walk(func(path string, dir fs.DirEntry) error {
+		if !strings.HasSuffix(path, ".gx") {
+			return nil
+		}
+		pkg, err := mod.GXPathFromOS(path)
+		if err != nil || pkg == "" {
+			return err
+		}
+		files.list = nil
+		if err := files.collectGXImports(path, dir); err != nil {
+			return err
+		}
+		imports[pkg] = append(imports[pkg], files.list...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for pkg, imps := range imports {
+		imps = unique(imps)
+		sort.Strings(imps)
+		imports[pkg] = imps
+	}
+	return imports, nil
+}
+
+// Select restricts the packages of the workspace which are packed, bound,
+// and imported in the C archive to the packages matching Go-style patterns
+// and to their transitive GX imports. Patterns are either import paths or
+// paths relative to the current folder starting with ./ or ../. "..."
+// matches any string, as in the go command (e.g. ./models/...).
+// Import path patterns may also select the packages of a dependency module,
+// which are then packed and bound in the gxdeps folder of the main module.
+// A warning is printed for patterns matching no package, as in the go
+// command. All the packages are selected if no pattern is given.
+func (ws *Workspace) Select(patterns []string) error {
+	ws.depModules, ws.depPackages = nil, nil
+	if len(patterns) == 0 {
+		return nil
+	}
+	imports := make(map[string][]string)
+	for _, mod := range ws.Modules {
+		if err := addPackageImports(imports, mod); err != nil {
+			return err
+		}
+	}
+	var deps []*Dep
+	var roots []string
+	for _, pattern := range patterns {
+		match, err := ws.patternMatcher(pattern)
+		if err != nil {
+			return err
+		}
+		matched := matchPackages(imports, match)
+		if len(matched) == 0 && !isLocalPattern(pattern) {
+			if deps == nil {
+				if deps, err = Deps(ws); err != nil {
+					return err
+				}
+			}
+			depMod, err := ws.dependencyOf(deps, pattern)
+			if err != nil {
+				return err
+			}
+			if depMod != nil {
+				if err := addPackageImports(imports, depMod); err != nil {
+					return err
+				}
+				matched = matchPackages(imports, match)
+			}
+		}
+		if len(matched) == 0 {
+			log.Printf("WARNING: %q matched no GX packages", pattern)
+			continue
+		}
+		roots = append(roots, matched...)
+	}
+	if len(roots) == 0 {
+		return fmt.Errorf("no GX package matched %s", strings.Join(patterns, " "))
+	}
+	ws.selected = make(map[string]bool)
+	for len(roots) > 0 {
+		pkg := roots[len(roots)-1]
+		roots = roots[:len(roots)-1]
+		if ws.selected[pkg] {
+			continue
+		}
+		ws.selected[pkg] = true
+		for _, imp := range imports[pkg] {
+			// Only packages of the workspace modules and of the selected
+			// dependency modules are packed and bound.
+			if _, ok := imports[imp]; ok {
+				roots = append(roots, imp)
+			}
+		}
+	}
+	ws.depPackages = make(map[string]bool)
+	for pkg := range ws.selected {
+		if ws.moduleOf(pkg) == nil {
+			ws.depPackages[pkg] = true
+		}
+	}
+	return nil
+}
+
+// addDependencies adds the dependency packages selected in another workspace,
+// for example the workspace of a target, to the packages packed and bound.
+func (ws *Workspace) addDependencies(other *Workspace) {
+	for _, mod := range other.depModules {
+		if !ws.isBoundDependency(mod.Name()) {
+			ws.depModules = append(ws.depModules, mod)
+		}
+	}
+	if len(other.depPackages) > 0 && ws.depPackages == nil {
+		ws.depPackages = make(map[string]bool)
+	}
+	for pkg := range other.depPackages {
+		ws.depPackages[pkg] = true
+	}
+}
+
+func addPackageImports(imports map[string][]string, mod *gxmodule.Module) error {
+	modImports, err := packageImports(mod)
+	if err != nil {
+		return err
+	}
+	for pkg, imps := range modImports {
+		imports[pkg] = imps
+	}
+	return nil
+}
+
+func matchPackages(imports map[string][]string, match func(string) bool) []string {
+	var pkgs []string
+	for pkg := range imports {
+		if match(pkg) {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	return pkgs
+}
+
+// dependencyOf returns the dependency module providing the packages of an
+// import path pattern and adds it to the modules of which packages are bound.
+// Returns nil if no dependency with GX source files matches the pattern.
+func (ws *Workspace) dependencyOf(deps []*Dep, pattern string) (*gxmodule.Module, error) {
+	var found *Dep
+	for _, dep := range deps {
+		if dep.GXFiles == 0 || dep.Dir == "" {
+			continue
+		}
+		if pattern != dep.Path && !strings.HasPrefix(pattern, dep.Path+"/") {
+			continue
+		}
+		// Nested modules: select the longest module path.
+		if found == nil || len(dep.Path) > len(found.Path) {
+			found = dep
+		}
+	}
+	if found == nil {
+		return nil, nil
+	}
+	for _, mod := range ws.depModules {
+		if mod.Name() == found.Path {
+			return mod, nil
+		}
+	}
+	mod, err := gxmodule.New(found.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load module %s: %v", found.Path, err)
+	}
+	if mod.Name() != found.Path {
+		return nil, fmt.Errorf("cannot load module %s: %s has no go.mod file", found.Path, found.Dir)
+	}
+	ws.depModules = append(ws.depModules, mod)
+	return mod, nil
+}
+
+// boundModules returns the modules of which GX packages are packed and bound:
+// the modules of the workspace and the dependency modules selected by patterns.
+func (ws *Workspace) boundModules() []*gxmodule.Module {
+	return append(slices.Clip(ws.Modules), ws.depModules...)
+}
+
+// isDependency returns true if a module is a dependency module selected by patterns.
+func (ws *Workspace) isDependency(mod *gxmodule.Module) bool {
+	return slices.Contains(ws.depModules, mod)
+}
+
+// isBoundDependency returns true if packages of a dependency module,
+// given its path, have been selected by patterns.
+func (ws *Workspace) isBoundDependency(path string) bool {
+	return slices.ContainsFunc(ws.depModules, func(mod *gxmodule.Module) bool {
+		return mod.Name() == path
+	})
+}
+
+// packagerModule returns the module in which the packagers of the GX packages
+// of a module are generated. Packagers of dependency modules are generated in
+// the main module.
+func (ws *Workspace) packagerModule(mod *gxmodule.Module) *gxmodule.Module {
+	if ws.isDependency(mod) {
+		return ws.Main
+	}
+	return mod
+}
+
+// patternMatcher returns a function matching GX package paths with a pattern.
+func (ws *Workspace) patternMatcher(pattern string) (func(string) bool, error) {
+	if isLocalPattern(pattern) {
+		path, err := ws.importPathOf(pattern)
+		if err != nil {
+			return nil, err
+		}
+		pattern = path
+	}
+	return matchPattern(pattern), nil
+}
+
+func isLocalPattern(pattern string) bool {
+	return pattern == "." || pattern == ".." ||
+		strings.HasPrefix(pattern, "./") || strings.HasPrefix(pattern, "../") ||
+		filepath.IsAbs(pattern)
+}
+
+// importPathOf converts a pattern relative to the current folder
+// into an import path pattern. The folder belongs to the module of the
+// workspace with the deepest root containing it.
+func (ws *Workspace) importPathOf(pattern string) (string, error) {
+	abs, err := filepath.Abs(filepath.FromSlash(pattern))
+	if err != nil {
+		return "", err
+	}
+	var found *gxmodule.Module
+	var foundRel string
+	for _, mod := range ws.members {
+		rel, err := filepath.Rel(mod.Root(), abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if found == nil || len(mod.Root()) > len(found.Root()) {
+			found, foundRel = mod, rel
+		}
+	}
+	switch {
+	case found == nil:
+		return "", fmt.Errorf("directory %s is outside the modules %s", pattern, ws.moduleNames())
+	case foundRel == ".":
+		return found.Name(), nil
+	case foundRel == "...":
+		return found.Name() + "/...", nil
+	}
+	return found.Name() + "/" + filepath.ToSlash(foundRel), nil
+}
+
+func (ws *Workspace) moduleNames() string {
+	names := make([]string, len(ws.Modules))
+	for i, mod := range ws.Modules {
+		names[i] = mod.Name()
+	}
+	return strings.Join(names, ", ")
+}
+
+// matchPattern returns a function matching import paths with a pattern
+// in which "..." matches any string. As in the go command, a trailing
+// "/..." also matches the path without it.
+func matchPattern(pattern string) func(string) bool {
+	re := regexp.QuoteMeta(pattern)
+	re = strings.ReplaceAll(re, `\.\.\.`, `.*`)
+	if strings.HasSuffix(re, `/.*`) {
+		re = strings.TrimSuffix(re, `/.*`) + `(/.*)?`
+	}
+	reg := regexp.MustCompile(`^` + re + `$`)
+	return reg.MatchString
+}
+
+// packages returns the selected GX packages of a module.
+func (ws *Workspace) packages(mod *gxmodule.Module) ([]string, error) {
+	pkgs, err := Packages(mod)
+	if err != nil {
+		return nil, err
+	}
+	selected := ws.selected
+	if ws.isDependency(mod) {
+		selected = ws.depPackages
+	} else if selected == nil {
+		return pkgs, nil
+	}
+	return slices.DeleteFunc(pkgs, func(pkg string) bool {
+		return !selected[pkg]
+	}), nil
+}
@@ -0,0 +1,191 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gxtc
+
+import (
+	"bytes"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{pattern: "example.com/a", path: "example.com/a", want: true},
+		{pattern: "example.com/a", path: "example.com/a/b"},
+		{pattern: "example.com/a/...", path: "example.com/a", want: true},
+		{pattern: "example.com/a/...", path: "example.com/a/b/c", want: true},
+		{pattern: "example.com/a/...", path: "example.com/ab"},
+		{pattern: "example.com/a...", path: "example.com/ab", want: true},
+		{pattern: "example.com/.../c", path: "example.com/a/b/c", want: true},
+		{pattern: "example.com/.../c", path: "example.com/a/b/cd"},
+		{pattern: "example.com/a.b", path: "example.com/axb"},
+		{pattern: "...", path: "example.com/a", want: true},
+	}
+	for _, test := range tests {
+		if got := matchPattern(test.pattern)(test.path); got != test.want {
+			t.Errorf("matchPattern(%q)(%q) = %v, want %v", test.pattern, test.path, got, test.want)
+		}
+	}
+}
+
+func TestIsLocalPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    bool
+	}{
+		{pattern: ".", want: true},
+		{pattern: "..", want: true},
+		{pattern: "./...", want: true},
+		{pattern: "./models/...", want: true},
+		{pattern: "../b", want: true},
+		{pattern: "/abs/path", want: true},
+		{pattern: "example.com/a"},
+		{pattern: "..."},
+		{pattern: ".hidden"},
+		{pattern: "models/..."},
+	}
+	for _, test := range tests {
+		if got := isLocalPattern(test.pattern); got != test.want {
+			t.Errorf("isLocalPattern(%q) = %v, want %v", test.pattern, got, test.want)
+		}
+	}
+}
+
+func TestImportPathOf(t *testing.T) {
+	ws := nestedWorkspace(t)
+	root := filepath.Dir(ws.Main.Root())
+	tests := []struct {
+		name    string
+		wd      string
+		pattern string
+		want    string
+		wantErr string
+	}{
+		{name: "module root", wd: "a", pattern: ".", want: "example.com/a"},
+		{name: "all from root", wd: "a", pattern: "./...", want: "example.com/a/..."},
+		{name: "all from subfolder", wd: "a/pkg", pattern: "./...", want: "example.com/a/pkg/..."},
+		{name: "parent", wd: "a/pkg", pattern: "..", want: "example.com/a"},
+		{name: "sibling", wd: "a/pkg", pattern: "../other", want: "example.com/a/other"},
+		{name: "nested module", wd: "a", pattern: "./nested/pkg", want: "example.com/a/nested/pkg"},
+		{name: "nested module root", wd: "a", pattern: "./nested/...", want: "example.com/a/nested/..."},
+		{name: "other module", wd: "a", pattern: "../ab/pkg", want: "example.com/ab/pkg"},
+		{name: "absolute", wd: "ab", pattern: filepath.Join(root, "a", "pkg"), want: "example.com/a/pkg"},
+		{name: "outside", wd: "a", pattern: "../..", wantErr: "is outside the modules"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wd := filepath.Join(root, filepath.FromSlash(test.wd))
+			if err := os.MkdirAll(wd, 0755); err != nil {
+				t.Fatal(err)
+			}
+			t.Chdir(wd)
+			got, err := ws.importPathOf(test.pattern)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("importPathOf(%q) error = %v, want %q", test.pattern, err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("importPathOf(%q) = %q, want %q", test.pattern, got, test.want)
+			}
+		})
+	}
+}
+
+func TestSelect(t *testing.T) {
+	const (
+		a = "example.com/toolchain/a"
+		b = "example.com/toolchain/b"
+	)
+	tests := []struct {
+		name     string
+		wd       string
+		patterns []string
+		// absolute patterns are relative to the module root.
+		absolute    bool
+		want        []string
+		wantWarning string
+		wantErr     string
+	}{
+		{name: "all", wd: ".", patterns: []string{"./..."}, want: []string{a, b}},
+		{name: "all from subfolder", wd: "b", patterns: []string{"./..."}, want: []string{a, b}},
+		{name: "imports", wd: ".", patterns: []string{"./b"}, want: []string{a, b}},
+		{name: "no import", wd: "b", patterns: []string{"../a"}, want: []string{a}},
+		{name: "import path", wd: ".", patterns: []string{b}, want: []string{a, b}},
+		{name: "trailing dots match parent", wd: ".", patterns: []string{a + "/..."}, want: []string{a}},
+		{name: "absolute", wd: "b", patterns: []string{"a"}, absolute: true, want: []string{a}},
+		{
+			name:        "warning",
+			wd:          ".",
+			patterns:    []string{"./a", "example.com/none/..."},
+			want:        []string{a},
+			wantWarning: `WARNING: "example.com/none/..." matched no GX packages`,
+		},
+		{
+			name:     "error",
+			wd:       ".",
+			patterns: []string{"example.com/none", "./c/..."},
+			wantErr:  "no GX package matched example.com/none ./c/...",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ws, _ := newTestWorkspace(t)
+			root := ws.Main.Root()
+			t.Chdir(filepath.Join(root, test.wd))
+			var logs bytes.Buffer
+			log.SetOutput(&logs)
+			defer log.SetOutput(os.Stderr)
+			patterns := slices.Clone(test.patterns)
+			if test.absolute {
+				for i, pattern := range patterns {
+					patterns[i] = filepath.Join(root, pattern)
+				}
+			}
+			err := ws.Select(patterns)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("Select(%q) error = %v, want %q", test.patterns, err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := slices.Sorted(maps.Keys(ws.selected)); !slices.Equal(got, test.want) {
+				t.Errorf("Select(%q) selected %q, want %q", test.patterns, got, test.want)
+			}
+			if !strings.Contains(logs.String(), test.wantWarning) {
+				t.Errorf("Select(%q) logged %q, want %q", test.patterns, logs.String(), test.wantWarning)
+			}
+			if test.wantWarning == "" && logs.Len() > 0 {
+				t.Errorf("Select(%q) logged %q, want no warning", test.patterns, logs.String())
+			}
+		})
+	}
+}
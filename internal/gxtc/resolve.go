@@ -0,0 +1,221 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gxtc
+
+import (
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gx-org/ccgx/internal/cmd/debug"
+	"github.com/gx-org/ccgx/internal/diag"
+	gxmodule "github.com/gx-org/gx/build/module"
+	"github.com/gx-org/gx/stdlib"
+	gomodule "golang.org/x/mod/module"
+)
+
+// CheckImports checks that all the imports of the selected GX packages
+// of a workspace resolve to a package of the GX standard library, of a
+// module of the workspace, or of a module required by the importing
+// module. Unresolved imports are reported as diagnostics.
+func CheckImports(ws *Workspace) (err error) {
+	span := debug.Start(debug.Step, "imports")
+	defer func() { span.End(err) }()
+	r := &importResolver{ws: ws, std: stdlib.Importer(nil)}
+	var diags []diag.Diagnostic
+	for _, mod := range ws.boundModules() {
+		modDiags, err := r.checkModule(mod)
+		if err != nil {
+			return err
+		}
+		diags = append(diags, modDiags...)
+	}
+	if len(diags) == 0 {
+		return nil
+	}
+	return &diag.Error{
+		Msg:         fmt.Sprintf("%d unresolved GX imports", len(diags)),
+		Diagnostics: diags,
+	}
+}
+
+type importResolver struct {
+	ws  *Workspace
+	std interface{ Support(string) bool }
+	// deps are the folders of the dependencies, loaded on demand.
+	deps map[string]*Dep
+	// locals are the GX packages of the modules of the workspace.
+	locals map[string]bool
+}
+
+func (r *importResolver) checkModule(mod *gxmodule.Module) ([]diag.Diagnostic, error) {
+	pkgs, err := r.ws.packages(mod)
+	if err != nil {
+		return nil, err
+	}
+	selected := make(map[string]bool)
+	for _, pkg := range pkgs {
+		selected[pkg] = true
+	}
+	var diags []diag.Diagnostic
+	files := gxFiles{mod: mod}
+	err = files.walk(func(path string, dir fs.DirEntry) error {
+		if !strings.HasSuffix(path, ".gx") {
+			return nil
+		}
+		pkg, err := mod.GXPathFromOS(path)
+		if err != nil || !selected[pkg] {
+			return err
+		}
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, path, nil, parser.ImportsOnly)
+		if err != nil {
+			return err
+		}
+		for _, imp := range file.Imports {
+			impPath, err := strconv.Unquote(imp.Path.Value)
+			if err != nil {
+				return fmt.Errorf("%s: import path %q is invalid: %v", path, imp.Path.Value, err)
+			}
+			msg, err := r.resolve(mod, impPath)
+			if err != nil {
+				return err
+			}
+			if msg == "" {
+				continue
+			}
+			pos := fset.Position(imp.Path.Pos())
+			diags = append(diags, diag.Diagnostic{
+				File:     path,
+				Line:     pos.Line,
+				Column:   pos.Column,
+				Severity: diag.SeverityError,
+				Message:  msg,
+				Tool:     "gx",
+			})
+		}
+		return nil
+	})
+	return diags, err
+}
+
+// resolve returns why an import of a module cannot be resolved.
+// Returns an empty string if the import resolves.
+func (r *importResolver) resolve(mod *gxmodule.Module, path string) (string, error) {
+	if r.std.Support(path) {
+		return "", nil
+	}
+	if member := r.ws.moduleOf(path); member != nil {
+		if err := r.loadLocals(); err != nil {
+			return "", err
+		}
+		if r.locals[path] {
+			return "", nil
+		}
+		return fmt.Sprintf("package %s not found in module %s", path, member.Name()), nil
+	}
+	req := requirementOf(mod, path)
+	if req.Path == "" {
+		return fmt.Sprintf("no required module provides GX package %s; to add it: go get %s", path, path), nil
+	}
+	dep, err := r.dep(req)
+	if err != nil {
+		return "", err
+	}
+	if dep.Dir == "" {
+		return fmt.Sprintf("module %s providing GX package %s is not downloaded; to download it: go mod download %s", req.Path, path, req.Path), nil
+	}
+	rel := strings.TrimPrefix(strings.TrimPrefix(path, req.Path), "/")
+	files, err := gxSourceFiles(filepath.Join(dep.Dir, filepath.FromSlash(rel)))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+	if len(files) > 0 {
+		return "", nil
+	}
+	return fmt.Sprintf("package %s not found in module %s (%s)", path, req.Path, dep.Dir), nil
+}
+
+// requirementOf returns the module required by a module providing a package.
+// Returns a zero version if no requirement matches.
+func requirementOf(mod *gxmodule.Module, path string) gomodule.Version {
+	var found gomodule.Version
+	for _, req := range mod.File().Require {
+		p := req.Mod.Path
+		if path != p && !strings.HasPrefix(path, p+"/") {
+			continue
+		}
+		// Nested modules: select the longest module path.
+		if len(p) > len(found.Path) {
+			found = req.Mod
+		}
+	}
+	return found
+}
+
+// dep returns the dependency of a required module.
+// Indirect requirements, not in the dependencies of the workspace,
+// are loaded on demand.
+func (r *importResolver) dep(req gomodule.Version) (*Dep, error) {
+	if err := r.loadDeps(); err != nil {
+		return nil, err
+	}
+	if dep := r.deps[req.Path]; dep != nil {
+		return dep, nil
+	}
+	deps, err := ModuleDeps(r.ws, []*gomodule.Version{&req})
+	if err != nil {
+		return nil, err
+	}
+	r.deps[req.Path] = deps[0]
+	return deps[0], nil
+}
+
+func (r *importResolver) loadDeps() error {
+	if r.deps != nil {
+		return nil
+	}
+	deps, err := Deps(r.ws)
+	if err != nil {
+		return err
+	}
+	r.deps = make(map[string]*Dep)
+	for _, dep := range deps {
+		r.deps[dep.Path] = dep
+	}
+	return nil
+}
+
+func (r *importResolver) loadLocals() error {
+	if r.locals != nil {
+		return nil
+	}
+	r.locals = make(map[string]bool)
+	for _, mod := range r.ws.members {
+		pkgs, err := Packages(mod)
+		if err != nil {
+			return err
+		}
+		for _, pkg := range pkgs {
+			r.locals[pkg] = true
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gxtc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	gxmodule "github.com/gx-org/gx/build/module"
+	gomodule "golang.org/x/mod/module"
+)
+
+func TestRequirementOf(t *testing.T) {
+	const goMod = `module example.com/app
+
+go 1.24.4
+
+require (
+	example.com/lib v1.0.0
+	example.com/lib/nested v0.2.0
+	example.com/other v0.1.0 // indirect
+)
+`
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte(goMod), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mod, err := gxmodule.New(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path string
+		want gomodule.Version
+	}{
+		{path: "example.com/lib", want: gomodule.Version{Path: "example.com/lib", Version: "v1.0.0"}},
+		{path: "example.com/lib/p", want: gomodule.Version{Path: "example.com/lib", Version: "v1.0.0"}},
+		{path: "example.com/lib/nested/p", want: gomodule.Version{Path: "example.com/lib/nested", Version: "v0.2.0"}},
+		{path: "example.com/other/p", want: gomodule.Version{Path: "example.com/other", Version: "v0.1.0"}},
+		{path: "example.com/library/p"},
+		{path: "example.com/app/p"},
+	}
+	for _, test := range tests {
+		if got := requirementOf(mod, test.path); got != test.want {
+			t.Errorf("requirementOf(%q) = %v, want %v", test.path, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,286 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gxtc
+
+import (
+	"bytes"
+	"debug/elf"
+	"debug/macho"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// TrimImports only imports in the C archive the Go packagers of the
+// selected GX packages. The packages they import, GX or Go, are only
+// included through them.
+var TrimImports bool
+
+// PackageSize is the size taken by a Go package in a C archive.
+type PackageSize struct {
+	// Package is the Go package path, or a description in parenthesis
+	// for the content not attributed to a Go package.
+	Package string
+	// Size in bytes of the symbols of the package.
+	Size int64
+}
+
+const (
+	cSymbols     = "(C symbols)"
+	otherObjects = "(cgo and C objects)"
+	goMetadata   = "(Go runtime metadata)"
+)
+
+// SizeReport returns the size of the symbols of a C archive grouped by Go
+// package, sorted by decreasing size. The Go code is in the go.o member of
+// the archive. The other members, compiled by cgo, are counted together.
+func SizeReport(path string) ([]PackageSize, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	sizes := make(map[string]int64)
+	err = readArchive(f, func(name string, member *io.SectionReader) error {
+		if name != "go.o" {
+			sizes[otherObjects] += member.Size()
+			return nil
+		}
+		syms, err := objectSymbols(member)
+		if err != nil {
+			return fmt.Errorf("%s: cannot read the symbols of %s: %v", path, name, err)
+		}
+		for name, size := range syms {
+			sizes[symbolPackage(name)] += size
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	report := make([]PackageSize, 0, len(sizes))
+	for pkg, size := range sizes {
+		report = append(report, PackageSize{Package: pkg, Size: size})
+	}
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].Size != report[j].Size {
+			return report[i].Size > report[j].Size
+		}
+		return report[i].Package < report[j].Package
+	})
+	return report, nil
+}
+
+// objectSymbols returns the size of the symbols defined in an ELF
+// or Mach-O object file.
+func objectSymbols(r io.ReaderAt) (map[string]int64, error) {
+	var magic [4]byte
+	if _, err := r.ReadAt(magic[:], 0); err != nil {
+		return nil, err
+	}
+	if string(magic[:]) == elf.ELFMAG {
+		return elfSymbols(r)
+	}
+	obj, err := macho.NewFile(r)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported object format (only ELF and Mach-O are supported)")
+	}
+	defer obj.Close()
+	if obj.Symtab == nil {
+		return nil, nil
+	}
+	return machoSymbols(obj.Symtab.Syms, obj.Sections), nil
+}
+
+func elfSymbols(r io.ReaderAt) (map[string]int64, error) {
+	obj, err := elf.NewFile(r)
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+	syms, err := obj.Symbols()
+	if err != nil {
+		return nil, err
+	}
+	sizes := make(map[string]int64)
+	for _, sym := range syms {
+		if sym.Size == 0 || sym.Section == elf.SHN_UNDEF {
+			continue
+		}
+		sizes[sym.Name] += int64(sym.Size)
+	}
+	return sizes, nil
+}
+
+// machoSymbols returns the size of the symbols defined in the sections of
+// a Mach-O object file. Mach-O symbols have no size: a symbol extends to
+// the next symbol of its section or to the end of the section.
+func machoSymbols(syms []macho.Symbol, sections []*macho.Section) map[string]int64 {
+	const (
+		stab = 0xe0 // N_STAB: debugging symbol.
+		typ  = 0x0e // N_TYPE: type of the symbol.
+		sect = 0x0e // N_SECT: defined in the section Sect.
+	)
+	bySection := make(map[uint8][]macho.Symbol)
+	for _, sym := range syms {
+		if sym.Type&stab != 0 || sym.Type&typ != sect || sym.Sect == 0 || int(sym.Sect) > len(sections) {
+			continue
+		}
+		bySection[sym.Sect] = append(bySection[sym.Sect], sym)
+	}
+	sizes := make(map[string]int64)
+	for sectNum, syms := range bySection {
+		sort.SliceStable(syms, func(i, j int) bool { return syms[i].Value < syms[j].Value })
+		sec := sections[sectNum-1]
+		for i, sym := range syms {
+			end := sec.Addr + sec.Size
+			if i+1 < len(syms) {
+				end = syms[i+1].Value
+			}
+			if end <= sym.Value {
+				continue
+			}
+			// Mach-O prefixes C and Go symbol names with an underscore.
+			sizes[strings.TrimPrefix(sym.Name, "_")] += int64(end - sym.Value)
+		}
+	}
+	return sizes
+}
+
+// symbolPackage returns the Go package of a symbol.
+// The linker escapes the dots of the last element of package paths
+// (e.g. example.com/a%2ev1.F is the function F of example.com/a.v1).
+func symbolPackage(name string) string {
+	rest, isType := strings.CutPrefix(name, "type:")
+	if isType {
+		// Type descriptors and equality functions are attributed
+		// to the package of the type.
+		name = strings.TrimLeft(strings.TrimPrefix(rest, ".eq."), "*[]")
+	}
+	if strings.HasPrefix(name, "go:") || strings.HasPrefix(name, "type:") ||
+		strings.HasPrefix(name, "$") || strings.HasPrefix(name, ".") {
+		return goMetadata
+	}
+	// Type arguments of generic instantiations and function types
+	// may contain other packages.
+	if end := strings.IndexAny(name, "[("); end >= 0 {
+		name = name[:end]
+	}
+	slash := strings.LastIndex(name, "/")
+	dot := strings.Index(name[slash+1:], ".")
+	if dot < 0 {
+		if slash < 0 && !isType {
+			return cSymbols
+		}
+		// Builtin and composite types.
+		return goMetadata
+	}
+	pkg := name[:slash+1+dot]
+	if unescaped, err := url.PathUnescape(pkg); err == nil {
+		pkg = unescaped
+	}
+	return pkg
+}
+
+// readArchive calls fn for each member of a Unix ar archive.
+// Both GNU and BSD long member names are supported.
+func readArchive(r io.ReaderAt, fn func(name string, member *io.SectionReader) error) error {
+	const (
+		magic      = "!<arch>\n"
+		headerSize = 60
+	)
+	buf := make([]byte, len(magic))
+	if _, err := r.ReadAt(buf, 0); err != nil || string(buf) != magic {
+		return fmt.Errorf("not an archive file")
+	}
+	var longNames []byte
+	header := make([]byte, headerSize)
+	for off := int64(len(magic)); ; {
+		if _, err := r.ReadAt(header, off); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		size, err := strconv.ParseInt(strings.TrimSpace(string(header[48:58])), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid archive member header at offset %d", off)
+		}
+		name := strings.TrimSpace(string(header[:16]))
+		member := io.NewSectionReader(r, off+headerSize, size)
+		if n, found := strings.CutPrefix(name, "#1/"); found {
+			// BSD long name: the name precedes the content of the member.
+			nameLen, err := strconv.ParseInt(n, 10, 64)
+			if err != nil || nameLen > size {
+				return fmt.Errorf("invalid archive member name at offset %d", off)
+			}
+			buf := make([]byte, nameLen)
+			if _, err := member.ReadAt(buf, 0); err != nil {
+				return err
+			}
+			name = strings.TrimRight(string(buf), "\x00")
+			member = io.NewSectionReader(r, off+headerSize+nameLen, size-nameLen)
+		}
+		switch {
+		case name == "//":
+			// GNU table of long names.
+			longNames = make([]byte, size)
+			if _, err := member.ReadAt(longNames, 0); err != nil {
+				return err
+			}
+		case name == "/" || strings.HasPrefix(name, "__.SYMDEF") || name == "__.PKGDEF":
+			// Symbol table.
+		default:
+			if idx, err := strconv.Atoi(strings.TrimPrefix(name, "/")); err == nil && strings.HasPrefix(name, "/") && idx < len(longNames) {
+				name, _, _ = strings.Cut(string(longNames[idx:]), "/\n")
+			}
+			if err := fn(strings.TrimSuffix(name, "/"), member); err != nil {
+				return err
+			}
+		}
+		off += headerSize + size
+		if size%2 == 1 {
+			off++
+		}
+	}
+}
+
+// WriteSizeReport writes a size report in a table.
+func WriteSizeReport(w io.Writer, report []PackageSize) error {
+	var total int64
+	for _, pkg := range report {
+		total += pkg.Size
+	}
+	var b bytes.Buffer
+	tw := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "SIZE\tPERCENT\tPACKAGE")
+	for _, pkg := range report {
+		percent := 0.0
+		if total > 0 {
+			percent = 100 * float64(pkg.Size) / float64(total)
+		}
+		fmt.Fprintf(tw, "%d\t%.1f%%\t%s\n", pkg.Size, percent, pkg.Package)
+	}
+	fmt.Fprintf(tw, "%d\t100.0%%\t(total)\n", total)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
@@ -0,0 +1,213 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gxtc
+
+import (
+	"bytes"
+	"debug/macho"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestSymbolPackage(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "runtime.mallocgc", want: "runtime"},
+		{name: "runtime.(*mheap).alloc", want: "runtime"},
+		{name: "github.com/gx-org/gx/interp.(*Interp).Eval", want: "github.com/gx-org/gx/interp"},
+		{name: "example.com/a%2ev1.F", want: "example.com/a.v1"},
+		{name: "example.com/a%2ev1.(*T).M", want: "example.com/a.v1"},
+		{name: "gopkg.in/yaml%2ev3.Unmarshal", want: "gopkg.in/yaml.v3"},
+		// Generics.
+		{name: "example.com/a.F[go.shape.float32]", want: "example.com/a"},
+		{name: "example.com/a.F[example.com/b.T]", want: "example.com/a"},
+		{name: "example.com/a.(*List[go.shape.int]).Push", want: "example.com/a"},
+		// Types.
+		{name: "type:example.com/a.T", want: "example.com/a"},
+		{name: "type:*example.com/a.T", want: "example.com/a"},
+		{name: "type:[]*example.com/a.T", want: "example.com/a"},
+		{name: "type:.eq.example.com/a.T", want: "example.com/a"},
+		{name: "type:*example.com/a%2ev1.T", want: "example.com/a.v1"},
+		{name: "type:int", want: goMetadata},
+		{name: "type:map[string]example.com/a.T", want: goMetadata},
+		{name: "type:func(example.com/a.T)", want: goMetadata},
+		{name: "type:.namedata.*func()-", want: goMetadata},
+		// Runtime metadata.
+		{name: "go:string.*", want: goMetadata},
+		{name: "go:itab.*example.com/a.T,error", want: goMetadata},
+		{name: "$f64.3ff0000000000000", want: goMetadata},
+		// C symbols.
+		{name: "_cgo_topofstack", want: cSymbols},
+		{name: "x_cgo_init", want: cSymbols},
+	}
+	for _, test := range tests {
+		if got := symbolPackage(test.name); got != test.want {
+			t.Errorf("symbolPackage(%q) = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestMachOSymbols(t *testing.T) {
+	const (
+		defined   = 0x0f // N_SECT | N_EXT
+		undefined = 0x01 // N_UNDF | N_EXT
+		debug     = 0x24 // N_FUN
+	)
+	sections := []*macho.Section{
+		{SectionHeader: macho.SectionHeader{Name: "__text", Addr: 0x1000, Size: 0x100}},
+		{SectionHeader: macho.SectionHeader{Name: "__data", Addr: 0x2000, Size: 0x40}},
+	}
+	syms := []macho.Symbol{
+		{Name: "_runtime.main", Type: defined, Sect: 1, Value: 0x1040},
+		{Name: "_example.com/a%2ev1.F", Type: defined, Sect: 1, Value: 0x1000},
+		{Name: "__cgo_topofstack", Type: defined, Sect: 1, Value: 0x10f0},
+		{Name: "_example.com/a%2ev1.V", Type: defined, Sect: 2, Value: 0x2010},
+		{Name: "_malloc", Type: undefined},
+		{Name: "_runtime.main", Type: debug, Sect: 1, Value: 0x1040},
+	}
+	got := machoSymbols(syms, sections)
+	want := map[string]int64{
+		"example.com/a%2ev1.F": 0x40,
+		"runtime.main":         0xb0,
+		"_cgo_topofstack":      0x10,
+		"example.com/a%2ev1.V": 0x30,
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("machoSymbols() = %v, want %v", got, want)
+	}
+}
+
+type arMember struct {
+	name    string
+	content string
+}
+
+// arHeader returns the header of an archive member.
+func arHeader(name string, size int) string {
+	return fmt.Sprintf("%-16s%-12d%-6d%-6d%-8s%-10d`\n", name, 0, 0, 0, "644", size)
+}
+
+// writeArchive writes members with their raw header names.
+func writeArchive(members []arMember) []byte {
+	var b bytes.Buffer
+	b.WriteString("!<arch>\n")
+	for _, m := range members {
+		b.WriteString(arHeader(m.name, len(m.content)))
+		b.WriteString(m.content)
+		if len(m.content)%2 == 1 {
+			b.WriteString("\n")
+		}
+	}
+	return b.Bytes()
+}
+
+func TestReadArchive(t *testing.T) {
+	tests := []struct {
+		name    string
+		members []arMember
+		want    []arMember
+	}{
+		{
+			name: "short names",
+			members: []arMember{
+				{name: "/", content: "symbols"},
+				{name: "go.o/", content: "go object"},
+				{name: "000001.o/", content: "c"},
+			},
+			want: []arMember{
+				{name: "go.o", content: "go object"},
+				{name: "000001.o", content: "c"},
+			},
+		},
+		{
+			name: "GNU long names",
+			members: []arMember{
+				{name: "//", content: "a_very_long_object_name.o/\nanother_long_object_name.o/\n"},
+				{name: "/0", content: "first"},
+				{name: "/27", content: "second"},
+				{name: "go.o/", content: "go"},
+			},
+			want: []arMember{
+				{name: "a_very_long_object_name.o", content: "first"},
+				{name: "another_long_object_name.o", content: "second"},
+				{name: "go.o", content: "go"},
+			},
+		},
+		{
+			name: "BSD long names",
+			members: []arMember{
+				{name: "#1/20", content: "__.SYMDEF SORTED\x00\x00\x00\x00symbols"},
+				{name: "#1/28", content: "a_very_long_object_name.o\x00\x00\x00first"},
+				{name: "go.o", content: "go"},
+			},
+			want: []arMember{
+				{name: "a_very_long_object_name.o", content: "first"},
+				{name: "go.o", content: "go"},
+			},
+		},
+		{
+			name: "Go package definition",
+			members: []arMember{
+				{name: "__.PKGDEF", content: "go object"},
+				{name: "_go_.o", content: "code"},
+			},
+			want: []arMember{
+				{name: "_go_.o", content: "code"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got []arMember
+			err := readArchive(bytes.NewReader(writeArchive(test.members)), func(name string, member *io.SectionReader) error {
+				content, err := io.ReadAll(member)
+				got = append(got, arMember{name: name, content: string(content)})
+				return err
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("readArchive() members = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestReadArchiveErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "not an archive", data: "\x7fELF\x02\x01\x01"},
+		{name: "invalid size", data: "!<arch>\n" + arHeader("go.o/", 0)[:48] + "abcdefghij`\n"},
+		{name: "invalid BSD name", data: "!<arch>\n" + arHeader("#1/99", 2) + "go"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := readArchive(bytes.NewReader([]byte(test.data)), func(string, *io.SectionReader) error {
+				return nil
+			})
+			if err == nil {
+				t.Errorf("readArchive() returns no error")
+			}
+		})
+	}
+}
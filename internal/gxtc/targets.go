@@ -0,0 +1,173 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gxtc
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// DefaultBackend is the Go package providing the GX runtime
+// linked in C archives.
+const DefaultBackend = "github.com/gx-org/xlapjrt/cgx"
+
+// Target is a C archive built in gxdeps/<Name> from a subset
+// of the GX packages of a workspace.
+type Target struct {
+	// Name of the target, also used to name its CMake library.
+	Name string
+	// Backend is the Go package providing the GX runtime.
+	Backend string
+
+	// ws is the workspace restricted to the packages of the target.
+	ws *Workspace
+}
+
+// Workspace returns the workspace restricted to the packages of the target.
+func (t *Target) Workspace() *Workspace {
+	return t.ws
+}
+
+// ArchivePaths returns the paths of the C archives built for a workspace:
+// one per target, or gxdeps/carchive.a if the workspace has no target.
+func ArchivePaths(ws *Workspace) []string {
+	depsPath := filepath.Join(ws.Main.Root(), gxdepsFolderName)
+	if len(ws.Targets) == 0 {
+		return []string{filepath.Join(depsPath, basename+".a")}
+	}
+	paths := make([]string, len(ws.Targets))
+	for i, target := range ws.Targets {
+		paths[i] = filepath.Join(depsPath, target.Name, basename+".a")
+	}
+	return paths
+}
+
+var targetName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// AddTarget adds a C archive built from the GX packages matching
+// patterns and their transitive GX imports. Relative patterns are
+// relative to the root of the main module. When a workspace has
+// targets, CompileCArchive builds them instead of gxdeps/carchive.a.
+func (ws *Workspace) AddTarget(name string, patterns []string, backend string) error {
+	if err := ws.checkTargetName(name); err != nil {
+		return err
+	}
+	if len(patterns) == 0 {
+		return fmt.Errorf("target %s: no package", name)
+	}
+	if backend == "" {
+		backend = DefaultBackend
+	}
+	rooted := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		if isLocalPattern(pattern) && !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(ws.Main.Root(), filepath.FromSlash(pattern))
+		}
+		rooted[i] = pattern
+	}
+	tws := *ws
+	tws.selected = nil
+	tws.Targets = nil
+	if err := tws.Select(rooted); err != nil {
+		return fmt.Errorf("target %s: %v", name, err)
+	}
+	// Dependency packages of the target are packed and bound with the workspace.
+	ws.addDependencies(&tws)
+	ws.Targets = append(ws.Targets, &Target{Name: name, Backend: backend, ws: &tws})
+	return nil
+}
+
+// checkTargetName checks that the folder of a target does not collide
+// with other files in gxdeps.
+func (ws *Workspace) checkTargetName(name string) error {
+	if !targetName.MatchString(name) {
+		return fmt.Errorf("invalid target name %q: only letters, digits, '_' and '-' are allowed", name)
+	}
+	if name == packagerFolderName {
+		return fmt.Errorf("invalid target name %q: reserved by ccgx", name)
+	}
+	for _, mod := range ws.members {
+		if first, _, _ := strings.Cut(mod.Name(), "/"); first == name {
+			return fmt.Errorf("invalid target name %q: conflicts with module %s in %s", name, mod.Name(), gxdepsFolderName)
+		}
+		for _, req := range mod.File().Require {
+			if first, _, _ := strings.Cut(req.Mod.Path, "/"); first == name {
+				return fmt.Errorf("invalid target name %q: conflicts with module %s in %s", name, req.Mod.Path, gxdepsFolderName)
+			}
+		}
+	}
+	// Folders of targets built before contain their C archive source.
+	path := filepath.Join(ws.Main.Root(), gxdepsFolderName, name)
+	if _, err := os.Lstat(path); err == nil {
+		if _, err := os.Stat(filepath.Join(path, basename+".go")); err != nil {
+			return fmt.Errorf("invalid target name %q: conflicts with %s", name, path)
+		}
+	}
+	for _, target := range ws.Targets {
+		if target.Name == name {
+			return fmt.Errorf("target %s defined twice", name)
+		}
+	}
+	return nil
+}
+
+const targetCMakeSource = `
+cmake_minimum_required (VERSION 3.24)
+project (%[1]s_carchive)
+
+add_library (%[1]s_carchive %[2]s%[3]s)
+target_include_directories (%[1]s_carchive %[4]s ${CMAKE_CURRENT_LIST_DIR} ${CMAKE_CURRENT_LIST_DIR}/..)
+target_link_libraries (%[1]s_carchive %[4]s ${CMAKE_CURRENT_LIST_DIR}/%[5]s.a)
+`
+
+// writeTargetCMakeLists writes the CMakeLists.txt of a target. It defines
+// a <name>_carchive library with the C++ bindings of the packages of the
+// target linked with its C archive. The library only carries the C archive
+// if the packages have not been bound yet.
+func writeTargetCMakeLists(target *Target, depsPath string) error {
+	var sources strings.Builder
+	for _, mod := range target.ws.boundModules() {
+		pkgs, err := target.ws.packages(mod)
+		if err != nil {
+			return err
+		}
+		for _, pkgPath := range pkgs {
+			paths, err := filepath.Glob(filepath.Join(depsPath, filepath.FromSlash(pkgPath), "*.cc"))
+			if err != nil {
+				return err
+			}
+			for _, path := range paths {
+				rel, err := filepath.Rel(depsPath, path)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(&sources, "\n  ${CMAKE_CURRENT_LIST_DIR}/../%s", filepath.ToSlash(rel))
+			}
+		}
+	}
+	kind, scope := "STATIC", "PUBLIC"
+	if sources.Len() == 0 {
+		kind, scope = "INTERFACE", "INTERFACE"
+	}
+	text := fmt.Sprintf(targetCMakeSource, target.Name, kind, sources.String(), scope, basename)
+	path := filepath.Join(depsPath, target.Name, "CMakeLists.txt")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return WriteFile(path, []byte(text), 0644)
+}
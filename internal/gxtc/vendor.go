@@ -0,0 +1,59 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gxtc
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/gx-org/ccgx/internal/cmd/debug"
+	"github.com/gx-org/ccgx/internal/gotc"
+)
+
+// Vendor copies the dependencies of a workspace in the vendor folder.
+// Go packages are vendored by the Go toolchain. The complete source of
+// dependencies providing GX source files or C/C++ headers is then copied
+// so that GX packages can be bound without the Go module cache.
+func Vendor(ws *Workspace) (err error) {
+	span := debug.Start(debug.Step, "vendor")
+	defer func() { span.End(err) }()
+	root := ws.Main.Root()
+	// The C archive sources are written first so that their dependencies are vendored.
+	if err := WriteCArchiveSource(ws); err != nil {
+		return err
+	}
+	if err := ModTidy(ws); err != nil {
+		return err
+	}
+	// Resolve the dependencies before the vendor folder is rebuilt.
+	deps, err := deps(ws, gotc.SourceModuleDirs)
+	if err != nil {
+		return err
+	}
+	if err := gotc.ModVendor(root); err != nil {
+		return err
+	}
+	vendor := gotc.VendorPath(root)
+	for _, dep := range deps {
+		if !dep.Linked() || ws.member(dep.Path) != nil {
+			continue
+		}
+		target := filepath.Join(vendor, filepath.FromSlash(dep.Path))
+		if err := copyDir(dep.Dir, target, skipModuleEntry); err != nil {
+			return fmt.Errorf("cannot vendor module %s: %v", dep.Path, err)
+		}
+	}
+	return nil
+}
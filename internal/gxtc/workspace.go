@@ -0,0 +1,229 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gxtc
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/gx-org/ccgx/internal/gotc"
+	"github.com/gx-org/gx/build/importers/localfs"
+	gxmodule "github.com/gx-org/gx/build/module"
+	"golang.org/x/mod/modfile"
+	gomodule "golang.org/x/mod/module"
+)
+
+// Workspace is the set of GX modules processed together by ccgx.
+// Outside of a Go workspace, it only contains the current module.
+type Workspace struct {
+	// Main is the current module. Dependencies are linked in its gxdeps
+	// folder and the C archive is built from it.
+	Main *gxmodule.Module
+	// Modules are the modules for which GX packages are packed and bound.
+	Modules []*gxmodule.Module
+	// WorkFile is the path to the go.work file.
+	// Empty if the current module is not part of a Go workspace.
+	WorkFile string
+	// Targets are the C archives built from subsets of the GX packages.
+	// A single C archive with all the packages is built if empty.
+	Targets []*Target
+	// Require are modules required by the main module even if no GX
+	// package imports them yet. They are required again after tidying.
+	Require []gomodule.Version
+
+	// members are all the modules of the workspace,
+	// including the modules not selected.
+	members []*gxmodule.Module
+	// selected are the GX packages selected by patterns.
+	// All the packages are processed if nil.
+	selected map[string]bool
+	// depModules are the dependency modules of which
+	// GX packages have been selected by patterns.
+	depModules []*gxmodule.Module
+	// depPackages are the selected GX packages of dependency modules,
+	// including the packages selected by targets.
+	depPackages map[string]bool
+}
+
+// CurrentWorkspace returns the workspace of the current module.
+// If the current module is part of a Go workspace, all the modules
+// used by the workspace are included unless a list of module paths
+// is given to select a subset of the modules. Modules of the workspace
+// imported by the selected modules are always included.
+func CurrentWorkspace(selected []string) (*Workspace, error) {
+	mod, err := gxmodule.Current()
+	if err != nil {
+		return nil, err
+	}
+	return NewWorkspace(mod, selected)
+}
+
+// NewWorkspace returns the workspace of a module.
+// See CurrentWorkspace for the selection of modules.
+func NewWorkspace(mod *gxmodule.Module, selected []string) (*Workspace, error) {
+	ws := &Workspace{
+		Main:     mod,
+		Modules:  []*gxmodule.Module{mod},
+		WorkFile: gotc.WorkFile(mod.Root()),
+		members:  []*gxmodule.Module{mod},
+	}
+	if ws.WorkFile == "" {
+		if len(selected) > 0 {
+			return nil, fmt.Errorf("cannot select modules %v: module %s is not part of a Go workspace", selected, mod.Name())
+		}
+		return ws, nil
+	}
+	data, err := os.ReadFile(ws.WorkFile)
+	if err != nil {
+		return nil, err
+	}
+	work, err := modfile.ParseWork(ws.WorkFile, data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse %s: %v", ws.WorkFile, err)
+	}
+	for _, use := range work.Use {
+		dir := use.Path
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(filepath.Dir(ws.WorkFile), dir)
+		}
+		useMod, err := gxmodule.New(dir)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load workspace module %s: %v", use.Path, err)
+		}
+		if useMod.Root() == mod.Root() {
+			continue
+		}
+		ws.members = append(ws.members, useMod)
+	}
+	ws.Modules = ws.members
+	if len(selected) == 0 {
+		return ws, nil
+	}
+	for _, path := range selected {
+		if ws.member(path) == nil {
+			return nil, fmt.Errorf("module %s not found in workspace %s", path, ws.WorkFile)
+		}
+	}
+	ws.Modules = slices.DeleteFunc(slices.Clone(ws.members), func(mod *gxmodule.Module) bool {
+		return !slices.Contains(selected, mod.Name())
+	})
+	if err := ws.addImportedMembers(); err != nil {
+		return nil, err
+	}
+	return ws, nil
+}
+
+// addImportedMembers adds to the selected modules the modules of the
+// workspace transitively imported by GX packages of the selected modules.
+// Their packagers are imported by the packagers of the selected modules:
+// they need to be generated as well.
+func (ws *Workspace) addImportedMembers() error {
+	added := make(map[*gxmodule.Module]bool)
+	for _, mod := range ws.Modules {
+		added[mod] = true
+	}
+	queue := slices.Clone(ws.Modules)
+	for len(queue) > 0 {
+		mod := queue[0]
+		queue = queue[1:]
+		imports, err := packageImports(mod)
+		if err != nil {
+			return err
+		}
+		for _, imps := range imports {
+			for _, imp := range imps {
+				member := ws.moduleOf(imp)
+				if member == nil || added[member] {
+					continue
+				}
+				added[member] = true
+				queue = append(queue, member)
+			}
+		}
+	}
+	ws.Modules = slices.DeleteFunc(slices.Clone(ws.members), func(mod *gxmodule.Module) bool {
+		return !added[mod]
+	})
+	return nil
+}
+
+// Members returns all the modules of the workspace,
+// including the modules not selected.
+func (ws *Workspace) Members() []*gxmodule.Module {
+	return ws.members
+}
+
+// member returns the module of the workspace given its path.
+// Returns nil if the module is not part of the workspace.
+func (ws *Workspace) member(path string) *gxmodule.Module {
+	for _, mod := range ws.members {
+		if mod.Name() == path {
+			return mod
+		}
+	}
+	return nil
+}
+
+// moduleOf returns the module of the workspace to which a package belongs,
+// that is the member with the longest module path prefixing the package path.
+// Returns nil if the package does not belong to any module of the workspace.
+func (ws *Workspace) moduleOf(importPath string) *gxmodule.Module {
+	var found *gxmodule.Module
+	for _, mod := range ws.members {
+		if belongs(mod.Name(), importPath) && (found == nil || len(mod.Name()) > len(found.Name())) {
+			found = mod
+		}
+	}
+	return found
+}
+
+// belongs returns true if a package path is in a module path,
+// e.g. example.com/a/b is in example.com/a but example.com/ab is not.
+func belongs(modPath, importPath string) bool {
+	return importPath == modPath || strings.HasPrefix(importPath, modPath+"/")
+}
+
+// goImport returns the Go package to import for a GX package.
+// Packages of the workspace and bound packages of dependencies
+// are imported from the Go packagers generated by ccgx.
+func (ws *Workspace) goImport(gxPkg string) string {
+	if mod := ws.moduleOf(gxPkg); mod != nil {
+		return packagerPath(mod, gxPkg)
+	}
+	// Bound packages of dependency modules are imported from
+	// the packagers generated in the main module.
+	if ws.depPackages[gxPkg] {
+		return packagerPath(ws.Main, gxPkg)
+	}
+	return gxPkg
+}
+
+// workspaceImporter imports packages from the source folder of a module
+// of the workspace. Unlike localfs.Importer, packages from the dependencies
+// of the module are not supported.
+type workspaceImporter struct {
+	*localfs.Importer
+	ws *Workspace
+}
+
+// Support returns true if the package belongs to the module and not to
+// a module of the workspace nested in it.
+func (imp workspaceImporter) Support(path string) bool {
+	mod := imp.ws.moduleOf(path)
+	return mod != nil && mod.Name() == imp.Module().Name()
+}
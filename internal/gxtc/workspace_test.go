@@ -0,0 +1,79 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gxtc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	gxmodule "github.com/gx-org/gx/build/module"
+)
+
+// newModule writes a go.mod file declaring a module in dir
+// and returns the module.
+func newModule(t *testing.T, dir, name string) *gxmodule.Module {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module "+name+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mod, err := gxmodule.New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mod
+}
+
+// nestedWorkspace returns a workspace with the module example.com/a,
+// the module example.com/a/nested in a subfolder of example.com/a,
+// and the module example.com/ab.
+func nestedWorkspace(t *testing.T) *Workspace {
+	t.Helper()
+	root := t.TempDir()
+	a := newModule(t, filepath.Join(root, "a"), "example.com/a")
+	nested := newModule(t, filepath.Join(root, "a", "nested"), "example.com/a/nested")
+	ab := newModule(t, filepath.Join(root, "ab"), "example.com/ab")
+	members := []*gxmodule.Module{a, nested, ab}
+	return &Workspace{Main: a, Modules: members, members: members}
+}
+
+func TestModuleOf(t *testing.T) {
+	ws := nestedWorkspace(t)
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "example.com/a", want: "example.com/a"},
+		{path: "example.com/a/pkg", want: "example.com/a"},
+		{path: "example.com/a/nested", want: "example.com/a/nested"},
+		{path: "example.com/a/nested/pkg", want: "example.com/a/nested"},
+		{path: "example.com/a/nestedpkg", want: "example.com/a"},
+		{path: "example.com/ab/pkg", want: "example.com/ab"},
+		{path: "example.com/abc"},
+		{path: "example.com"},
+	}
+	for _, test := range tests {
+		got := ""
+		if mod := ws.moduleOf(test.path); mod != nil {
+			got = mod.Name()
+		}
+		if got != test.want {
+			t.Errorf("moduleOf(%q) = %q, want %q", test.path, got, test.want)
+		}
+	}
+}
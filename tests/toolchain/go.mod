@@ -0,0 +1,5 @@
+module example.com/toolchain
+
+go 1.24.4
+
+require example.com/dep v1.0.0